@@ -0,0 +1,7 @@
+data "fal_app_revisions" "sana" {
+  name = fal_app.sana_app.name
+}
+
+output "previous_revision" {
+  value = data.fal_app_revisions.sana.revisions[1].id
+}
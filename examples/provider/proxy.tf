@@ -0,0 +1,6 @@
+provider "fal" {
+  proxy = {
+    url      = "http://proxy.example.com:3128"
+    no_proxy = "localhost,.internal.example.com"
+  }
+}
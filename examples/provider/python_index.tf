@@ -0,0 +1,7 @@
+provider "fal" {
+  python_index = {
+    url      = "https://artifactory.example.com/api/pypi/pypi/simple"
+    username = "deploy"
+    password = var.artifactory_token
+  }
+}
@@ -0,0 +1,3 @@
+provider "fal" {
+  runner = "pip"
+}
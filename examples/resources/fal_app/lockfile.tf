@@ -0,0 +1,7 @@
+resource "fal_app" "sana_app" {
+  entrypoint    = "sana.py"
+  lockfile_mode = "locked"
+  git = {
+    url = "https://github.com/example/sana.git"
+  }
+}
@@ -0,0 +1,9 @@
+resource "fal_app" "sana_app" {
+  entrypoint = "sana.py"
+  git = {
+    url          = "https://github.com/example/monorepo.git"
+    branch       = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+    path         = "services/sana"
+    sparse_paths = ["services/sana", "libs/common"]
+  }
+}
@@ -0,0 +1,16 @@
+resource "tls_private_key" "tls-key" {
+  algorithm   = "ED25519"
+  ecdsa_curve = "P256"
+}
+
+resource "fal_app" "sana_app" {
+  entrypoint = "fal_demos/image/sana.py"
+  git = {
+    url    = "git@github.com:fal-ai-community/fal-demos.git"
+    branch = "main" # you can place commit hashes here too
+    ssh = {
+      username    = "git"
+      private_key = tls_private_key.tls-key.private_key_openssh
+    }
+  }
+}
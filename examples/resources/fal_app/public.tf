@@ -0,0 +1,6 @@
+resource "fal_app" "sana_app" {
+  entrypoint = "fal_demos/image/sana.py"
+  git = {
+    url = "https://github.com/fal-ai-community/fal-demos.git"
+  }
+}
@@ -0,0 +1 @@
+terraform import fal_app_alias.sana_prod sana-prod
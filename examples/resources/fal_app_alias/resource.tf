@@ -0,0 +1,11 @@
+resource "fal_app" "sana_staging" {
+  entrypoint = "fal_demos/image/sana.py"
+  git = {
+    url = "https://github.com/fal-ai-community/fal-demos.git"
+  }
+}
+
+resource "fal_app_alias" "sana_prod" {
+  name        = "sana-prod"
+  revision_id = "3f1c9a52-7e0b-4d8e-9a51-2b6f0c4d7e19"
+}
@@ -0,0 +1,139 @@
+package fal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/fal"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AppRevisionsDataSource{}
+
+func NewAppRevisionsDataSource() datasource.DataSource {
+	return &AppRevisionsDataSource{}
+}
+
+// AppRevisionsDataSource defines the data source implementation.
+type AppRevisionsDataSource struct {
+	client *fal.Client
+}
+
+type AppRevisionModel struct {
+	ID          types.String `tfsdk:"id"`
+	CreatedAt   types.String `tfsdk:"created_at"`
+	Active      types.Bool   `tfsdk:"active"`
+	MachineType types.String `tfsdk:"machine_type"`
+	GitCommit   types.String `tfsdk:"git_commit"`
+}
+
+// AppRevisionsDataSourceModel describes the data source data model.
+type AppRevisionsDataSourceModel struct {
+	Name      types.String       `tfsdk:"name"`
+	Revisions []AppRevisionModel `tfsdk:"revisions"`
+}
+
+func (d *AppRevisionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_app_revisions"
+}
+
+func (d *AppRevisionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The fal_app_revisions data source lists the revisions deployed for a fal app, newest first.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The app's name",
+				Required:            true,
+			},
+			"revisions": schema.ListNestedAttribute{
+				MarkdownDescription: "The app's revisions, newest first",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The revision id",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "The timestamp for when the revision was deployed",
+							Computed:            true,
+						},
+						"active": schema.BoolAttribute{
+							MarkdownDescription: "Whether the app is currently served from this revision",
+							Computed:            true,
+						},
+						"machine_type": schema.StringAttribute{
+							MarkdownDescription: "The machine type the revision runs on",
+							Computed:            true,
+						},
+						"git_commit": schema.StringAttribute{
+							MarkdownDescription: "The git commit the revision was deployed from, if known",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *AppRevisionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*fal.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *fal.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+	d.client = client
+}
+
+func (d *AppRevisionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AppRevisionsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	revisions, err := d.client.ListRevisions(ctx, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", "Unable to read app revisions, got error: "+err.Error())
+		return
+	}
+
+	data.Revisions = make([]AppRevisionModel, 0, len(revisions))
+	for _, r := range revisions {
+		data.Revisions = append(data.Revisions, AppRevisionModel{
+			ID:          types.StringValue(r.Revision),
+			CreatedAt:   stringOrNull(r.CreatedAt),
+			Active:      types.BoolValue(r.Active),
+			MachineType: stringOrNull(r.MachineType),
+			GitCommit:   stringOrNull(r.GitCommit),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func stringOrNull(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
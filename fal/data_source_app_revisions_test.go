@@ -0,0 +1,69 @@
+package fal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+const testAccAppRevisionsDataSourceName = "data.fal_app_revisions.test"
+
+func testAccAppRevisionsDataSourceConfig(url, authMode string) string {
+	return testAccAppConfig(url, authMode) + `
+data "fal_app_revisions" "test" {
+  name = fal_app.test.name
+}
+`
+}
+
+func TestAccAppRevisionsDataSource(t *testing.T) {
+	env := acctest.Setup(t)
+	repos := testAccAppRepositories(t)
+	srv := acctest.NewHTTPServer(t, repos)
+	url := srv.RepoURL(testAccAppRepoPath)
+
+	commit := repos[testAccAppRepoPath].Head(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckAppDestroyed(t, env),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAppConfig(url, "private"),
+			},
+			{
+				Config: testAccAppRevisionsDataSourceConfig(url, "public"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.#", "2"),
+					resource.TestCheckResourceAttrPair(testAccAppRevisionsDataSourceName, "revisions.0.id", testAccAppResourceName, "revision_id"),
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.0.active", "true"),
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.0.machine_type", "XS"),
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.0.git_commit", commit),
+					resource.TestCheckResourceAttrSet(testAccAppRevisionsDataSourceName, "revisions.0.created_at"),
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.1.id", "00000000-0000-4000-8000-000000000001"),
+					resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.1.active", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAppRevisionsDataSource_unknownApp(t *testing.T) {
+	acctest.Setup(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "fal_app_revisions" "test" {
+  name = %q
+}
+`, "missing"),
+				Check: resource.TestCheckResourceAttr(testAccAppRevisionsDataSourceName, "revisions.#", "0"),
+			},
+		},
+	})
+}
@@ -5,11 +5,19 @@ import (
 	"os"
 
 	"github.com/fal-ai/terraform-provider-fal/internal/fal"
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
+	"github.com/fal-ai/terraform-provider-fal/internal/runner"
+	"github.com/fal-ai/terraform-provider-fal/internal/validators"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 const (
@@ -19,7 +27,10 @@ const (
 var currentVersion = "1.0.0"
 
 // Ensure falProvider satisfies various provider interfaces
-var _ provider.Provider = &falProvider{}
+var (
+	_ provider.Provider                   = &falProvider{}
+	_ provider.ProviderWithValidateConfig = &falProvider{}
+)
 
 // falProvider defines the provider implementation.
 type falProvider struct {
@@ -28,7 +39,38 @@ type falProvider struct {
 
 // falProviderModel describes the provider data model.
 type falProviderModel struct {
-	FalKey types.String `tfsdk:"fal_key"`
+	FalKey      types.String         `tfsdk:"fal_key"`
+	Proxy       *falProxyModel       `tfsdk:"proxy"`
+	PythonIndex *falPythonIndexModel `tfsdk:"python_index"`
+	Runner      types.String         `tfsdk:"runner"`
+}
+
+type falProxyModel struct {
+	URL                  types.String `tfsdk:"url"`
+	NoProxy              types.String `tfsdk:"no_proxy"`
+	CertificateAuthority types.String `tfsdk:"certificate_authority"`
+}
+
+// falPythonIndexModel describes the python_index of the provider and of
+// fal_app.
+type falPythonIndexModel struct {
+	URL       types.String `tfsdk:"url"`
+	ExtraURLs types.List   `tfsdk:"extra_urls"`
+	Username  types.String `tfsdk:"username"`
+	Password  types.String `tfsdk:"password"`
+}
+
+func (m *falPythonIndexModel) pythonIndex(ctx context.Context) *runner.PythonIndex {
+	if m == nil {
+		return nil
+	}
+	index := &runner.PythonIndex{
+		URL:      m.URL.ValueString(),
+		Username: m.Username.ValueString(),
+		Password: m.Password.ValueString(),
+	}
+	m.ExtraURLs.ElementsAs(ctx, &index.ExtraURLs, false)
+	return index
 }
 
 func New(version string) func() provider.Provider {
@@ -52,6 +94,64 @@ func (p *falProvider) Schema(ctx context.Context, req provider.SchemaRequest, re
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"runner": schema.StringAttribute{
+				MarkdownDescription: "Tool managing the Python environments `fal` runs in. Available values: `uv`, `pip` (a `python3 -m venv` virtual environment, installing `requirements.txt` if the project has one and the project otherwise), `poetry`, and `fal` for images that ship the `fal` CLI and the dependencies of every app. Defaults to `uv`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(runner.Kinds...),
+				},
+			},
+			"proxy": schema.SingleNestedAttribute{
+				MarkdownDescription: "Proxy that git sources are fetched through and that the `runner` and `fal` use. SSH git sources only go through SOCKS5 proxies.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						MarkdownDescription: "URL of the proxy, e.g. `http://proxy.example.com:3128` or `socks5://proxy.example.com:1080`.",
+						Required:            true,
+						Validators: []validator.String{
+							validators.URLScheme("http", "https", "socks5", "socks5h"),
+						},
+					},
+					"no_proxy": schema.StringAttribute{
+						MarkdownDescription: "Comma-separated hosts, domains and CIDR ranges reached directly, in the `NO_PROXY` format.",
+						Optional:            true,
+					},
+					"certificate_authority": schema.StringAttribute{
+						MarkdownDescription: "PEM encoded certificate authority of a proxy that intercepts TLS. Git trusts it in addition to the system roots, the `runner` and `fal` instead of them.",
+						Optional:            true,
+					},
+				},
+			},
+			"python_index": schema.SingleNestedAttribute{
+				MarkdownDescription: "Python package indexes the `runner` installs `fal` and the dependencies of apps from instead of PyPI. Apps can set their own `python_index`. Not supported by the `poetry` runner, which uses the sources of `pyproject.toml`, and the `fal` runner.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						MarkdownDescription: "URL of the index replacing PyPI, e.g. `https://artifactory.example.com/api/pypi/pypi/simple`.",
+						Optional:            true,
+						Validators: []validator.String{
+							validators.URLScheme("http", "https"),
+						},
+					},
+					"extra_urls": schema.ListAttribute{
+						MarkdownDescription: "URLs of indexes searched before `url`, in order.",
+						ElementType:         types.StringType,
+						Optional:            true,
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(validators.URLScheme("http", "https")),
+						},
+					},
+					"username": schema.StringAttribute{
+						MarkdownDescription: "Username for the indexes.",
+						Optional:            true,
+					},
+					"password": schema.StringAttribute{
+						MarkdownDescription: "Password or token for the indexes.",
+						Optional:            true,
+						Sensitive:           true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -71,6 +171,32 @@ func getFalKey(data falProviderModel) (string, bool) {
 	return "", false
 }
 
+// ValidateConfig rejects a python_index the runner does not support at plan
+// time rather than when the first command runs.
+func (p *falProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var kind types.String
+	var index types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("runner"), &kind)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("python_index"), &index)...)
+	if resp.Diagnostics.HasError() || kind.IsUnknown() || !fullyKnown(ctx, index) {
+		return
+	}
+
+	opts := runner.Options{}
+	if !index.IsNull() {
+		var model falPythonIndexModel
+		resp.Diagnostics.Append(index.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		opts.PythonIndex = model.pythonIndex(ctx)
+	}
+
+	if err := runner.Validate(runner.Kind(kind.ValueString()), opts); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("python_index"), "Unsupported Runner Configuration", err.Error())
+	}
+}
+
 func (p *falProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	var data falProviderModel
 
@@ -90,7 +216,16 @@ func (p *falProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		return
 	}
 
-	client, err := fal.NewWithTemp(falKey)
+	var proxy *git.Proxy
+	if data.Proxy != nil {
+		proxy = &git.Proxy{
+			URL:      data.Proxy.URL.ValueString(),
+			NoProxy:  data.Proxy.NoProxy.ValueString(),
+			CABundle: []byte(data.Proxy.CertificateAuthority.ValueString()),
+		}
+	}
+
+	client, err := fal.NewWithTemp(falKey, proxy, data.PythonIndex.pythonIndex(ctx), runner.Kind(data.Runner.ValueString()))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create fal's api client",
@@ -104,9 +239,12 @@ func (p *falProvider) Configure(ctx context.Context, req provider.ConfigureReque
 func (p *falProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewAppResource,
+		NewAppAliasResource,
 	}
 }
 
 func (p *falProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewAppRevisionsDataSource,
+	}
 }
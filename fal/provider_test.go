@@ -0,0 +1,19 @@
+package fal
+
+import (
+	"testing"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/acctest"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// testAccProtoV6ProviderFactories instantiates the provider in-process for
+// acceptance tests.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"fal": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+func TestMain(m *testing.M) {
+	acctest.Main(m)
+}
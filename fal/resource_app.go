@@ -2,33 +2,54 @@ package fal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/fal-ai/terraform-provider-fal/internal/fal"
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
+	"github.com/fal-ai/terraform-provider-fal/internal/runner"
 	"github.com/fal-ai/terraform-provider-fal/internal/validators"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 const (
-	defaultBranch   = "main"
 	defaultStrategy = "rolling"
 	defaultAuthMode = "private"
+	defaultGitDepth = 1
+
+	defaultHealthCheckStatus   = 200
+	defaultHealthCheckTimeout  = 30
+	defaultHealthCheckRetries  = 10
+	defaultHealthCheckInterval = 10
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var (
-	_ resource.Resource                = &AppResource{}
-	_ resource.ResourceWithImportState = &AppResource{}
+	_ resource.Resource                   = &AppResource{}
+	_ resource.ResourceWithImportState    = &AppResource{}
+	_ resource.ResourceWithModifyPlan     = &AppResource{}
+	_ resource.ResourceWithValidateConfig = &AppResource{}
 )
 
 func NewAppResource() resource.Resource {
@@ -41,36 +62,83 @@ type AppResource struct {
 }
 
 type SSH struct {
-	Username   types.String `tfsdk:"username"`
-	Password   types.String `tfsdk:"password"`
-	PrivateKey types.String `tfsdk:"private_key"`
+	Username           types.String `tfsdk:"username"`
+	Password           types.String `tfsdk:"password"`
+	PrivateKey         types.String `tfsdk:"private_key"`
+	UseAgent           types.Bool   `tfsdk:"use_agent"`
+	KnownHosts         types.String `tfsdk:"known_hosts"`
+	HostKeyFingerprint types.String `tfsdk:"host_key_fingerprint"`
 }
 
 type HTTP struct {
 	Username             types.String `tfsdk:"username"`
 	Password             types.String `tfsdk:"password"`
 	InsecureHTTPAllowed  types.Bool   `tfsdk:"allow_insecure_http"`
+	InsecureSkipVerify   types.Bool   `tfsdk:"insecure_skip_tls_verify"`
 	CertificateAuthority types.String `tfsdk:"certificate_authority"`
+	ClientCertificate    types.String `tfsdk:"client_certificate"`
+	ClientKey            types.String `tfsdk:"client_key"`
+	Token                types.String `tfsdk:"token"`
+	GitHubApp            *GitHubApp   `tfsdk:"github_app"`
+}
+
+type GitHubApp struct {
+	AppID          types.Int64  `tfsdk:"app_id"`
+	InstallationID types.Int64  `tfsdk:"installation_id"`
+	PrivateKey     types.String `tfsdk:"private_key"`
+	APIURL         types.String `tfsdk:"api_url"`
+}
+
+type VerifySignature struct {
+	GPGKeys           types.String `tfsdk:"gpg_keys"`
+	SSHAllowedSigners types.String `tfsdk:"ssh_allowed_signers"`
+	Tag               types.String `tfsdk:"tag"`
 }
 
 type Git struct {
-	URL    types.String `tfsdk:"url"`
-	Branch types.String `tfsdk:"branch"`
-	SSH    *SSH         `tfsdk:"ssh"`
-	HTTP   *HTTP        `tfsdk:"http"`
+	URL        types.String `tfsdk:"url"`
+	Branch     types.String `tfsdk:"branch"`
+	Path       types.String `tfsdk:"path"`
+	Depth      types.Int64  `tfsdk:"depth"`
+	Submodules types.Bool   `tfsdk:"submodules"`
+	LFS        types.Bool   `tfsdk:"lfs"`
+	SSH        *SSH         `tfsdk:"ssh"`
+	HTTP       *HTTP        `tfsdk:"http"`
+
+	SparsePaths     types.List       `tfsdk:"sparse_paths"`
+	VerifySignature *VerifySignature `tfsdk:"verify_signature"`
+}
+
+type HealthCheck struct {
+	URL            types.String `tfsdk:"url"`
+	Path           types.String `tfsdk:"path"`
+	ExpectedStatus types.Int64  `tfsdk:"expected_status"`
+	Payload        types.String `tfsdk:"payload"`
+	Timeout        types.Int64  `tfsdk:"timeout"`
+	Retries        types.Int64  `tfsdk:"retries"`
+	Interval       types.Int64  `tfsdk:"interval"`
+	Headers        types.Map    `tfsdk:"headers"`
 }
 
 // AppResourceModel describes the resource data model.
 type AppResourceModel struct {
-	Name       types.String `tfsdk:"name"`
-	RevisionID types.String `tfsdk:"revision_id"`
-
-	Entrypoint types.String `tfsdk:"entrypoint"`
-	Strategy   types.String `tfsdk:"strategy"`
-	AuthMode   types.String `tfsdk:"auth_mode"`
-	Git        types.Object `tfsdk:"git"`
-	CreatedAt  types.String `tfsdk:"created_at"`
-	UpdatedAt  types.String `tfsdk:"updated_at"`
+	Name        types.String `tfsdk:"name"`
+	RevisionID  types.String `tfsdk:"revision_id"`
+	EndpointURL types.String `tfsdk:"endpoint_url"`
+	QueueURL    types.String `tfsdk:"queue_url"`
+	WsURL       types.String `tfsdk:"ws_url"`
+
+	Entrypoint        types.String `tfsdk:"entrypoint"`
+	Strategy          types.String `tfsdk:"strategy"`
+	AuthMode          types.String `tfsdk:"auth_mode"`
+	Git               types.Object `tfsdk:"git"`
+	HealthCheck       types.Object `tfsdk:"health_check"`
+	PythonIndex       types.Object `tfsdk:"python_index"`
+	LockfileMode      types.String `tfsdk:"lockfile_mode"`
+	LockfileHash      types.String `tfsdk:"lockfile_hash"`
+	RollbackOnFailure types.Bool   `tfsdk:"rollback_on_failure"`
+	CreatedAt         types.String `tfsdk:"created_at"`
+	UpdatedAt         types.String `tfsdk:"updated_at"`
 }
 
 func (r *AppResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -83,16 +151,42 @@ func (r *AppResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
-				MarkdownDescription: "The app's name",
+				MarkdownDescription: "The app's name. Defaults to the name the app is registered under by `fal deploy`. Changing it forces a new app.",
+				Optional:            true,
 				Computed:            true,
+				Validators: []validator.String{
+					validators.AppName(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"revision_id": schema.StringAttribute{
 				MarkdownDescription: "The app's revision id",
 				Computed:            true,
 			},
+			"endpoint_url": schema.StringAttribute{
+				MarkdownDescription: "The app's synchronous endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"queue_url": schema.StringAttribute{
+				MarkdownDescription: "The app's queue endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ws_url": schema.StringAttribute{
+				MarkdownDescription: "The app's websocket endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"entrypoint": schema.StringAttribute{
 				MarkdownDescription: "The app deployment entrypoint",
 				Required:            true,
@@ -119,7 +213,7 @@ func (r *AppResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Description: "Configuration block with settings for fal's app deployer.",
 				Attributes: map[string]schema.Attribute{
 					"url": schema.StringAttribute{
-						Description: "URL of git repository to bootstrap from.",
+						Description: "URL of git repository to bootstrap from. Accepts http(s), ssh and scp-like (`git@github.com:owner/repo.git`) URLs.",
 						Required:    true,
 						Validators: []validator.String{
 							validators.URLScheme("http", "https", "ssh"),
@@ -129,10 +223,39 @@ func (r *AppResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 						},
 					},
 					"branch": schema.StringAttribute{
-						Description: fmt.Sprintf("Branch in repository to use with deployment. Defaults to `%s`.", defaultBranch),
+						Description: "Branch in repository to use with deployment, or a full commit hash to pin the deployment to. Defaults to the repository's default branch.",
+						Optional:    true,
+					},
+					"path": schema.StringAttribute{
+						Description: "Directory of the project within the repository, relative to its root. `uv sync` and `fal deploy` run from it and `entrypoint` is resolved relative to it. Defaults to the repository root.",
+						Optional:    true,
+					},
+					"depth": schema.Int64Attribute{
+						Description: fmt.Sprintf("Number of commits of history to clone, `0` clones the full history. Commits pinned in `branch` are fetched directly or by deepening the clone until they are found. Defaults to `%d`.", defaultGitDepth),
+						Optional:    true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"sparse_paths": schema.ListAttribute{
+						Description: "Directories to check out, relative to the repository root. Files at the root are checked out too, like in git's sparse-checkout cone mode, and `path` must be in one of them. Defaults to the whole repository.",
+						ElementType: types.StringType,
+						Optional:    true,
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+							listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+						},
+					},
+					"submodules": schema.BoolAttribute{
+						Description: "Check out submodules recursively. They are fetched with the same credentials as the repository.",
+						Optional:    true,
+					},
+					"lfs": schema.BoolAttribute{
+						Description: "Fetch Git LFS objects of the repository and its submodules.",
 						Optional:    true,
 					},
 					"ssh": schema.SingleNestedAttribute{
+						Description: "SSH credentials for the repository. `uv` is given the private key and `known_hosts` too, to fetch git dependencies of the project over SSH.",
 						Attributes: map[string]schema.Attribute{
 							"username": schema.StringAttribute{
 								Description: "Username for Git SSH server.",
@@ -148,10 +271,29 @@ func (r *AppResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 								Optional:    true,
 								Sensitive:   true,
 							},
+							"use_agent": schema.BoolAttribute{
+								Description: "Authenticate with the keys of the SSH agent at `SSH_AUTH_SOCK` instead of `private_key`.",
+								Optional:    true,
+								Validators: []validator.Bool{
+									boolvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("private_key")),
+								},
+							},
+							"known_hosts": schema.StringAttribute{
+								Description: "known_hosts entries the Git SSH server's host key must match. Defaults to the known_hosts files of the machine running Terraform.",
+								Optional:    true,
+							},
+							"host_key_fingerprint": schema.StringAttribute{
+								Description: "SHA256 fingerprint the Git SSH server's host key must have, as printed by `ssh-keygen -l`, e.g. `SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8`.",
+								Optional:    true,
+								Validators: []validator.String{
+									stringvalidator.RegexMatches(regexp.MustCompile(`^SHA256:[A-Za-z0-9+/]{43}$`), "must be a SHA256 fingerprint as printed by `ssh-keygen -l`"),
+								},
+							},
 						},
 						Optional: true,
 					},
 					"http": schema.SingleNestedAttribute{
+						Description: "HTTP(S) credentials and TLS settings for the repository. `uv` is given them too, to fetch git dependencies of the project from the same host.",
 						Attributes: map[string]schema.Attribute{
 							"username": schema.StringAttribute{
 								Description: "Username for basic authentication.",
@@ -163,19 +305,213 @@ func (r *AppResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 								Sensitive:   true,
 							},
 							"allow_insecure_http": schema.BoolAttribute{
-								Description: "Allows HTTP Git URL connections.",
+								Description: "Allows plain HTTP Git URLs, which are rejected otherwise. Does not affect certificate verification of HTTPS URLs.",
+								Optional:    true,
+							},
+							"insecure_skip_tls_verify": schema.BoolAttribute{
+								Description: "Skips verification of the Git server's TLS certificate. Prefer `certificate_authority` for self-signed certificates.",
 								Optional:    true,
 							},
 							"certificate_authority": schema.StringAttribute{
 								Description: "Certificate authority to validate self-signed certificates.",
 								Optional:    true,
 							},
+							"client_certificate": schema.StringAttribute{
+								Description: "PEM encoded TLS client certificate presented to HTTPS Git servers that require mutual TLS.",
+								Optional:    true,
+								Validators: []validator.String{
+									stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("client_key")),
+								},
+							},
+							"client_key": schema.StringAttribute{
+								Description: "PEM encoded private key of `client_certificate`.",
+								Optional:    true,
+								Sensitive:   true,
+								Validators: []validator.String{
+									stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("client_certificate")),
+								},
+							},
+							"token": schema.StringAttribute{
+								Description: "Token sent as a bearer token instead of basic authentication. `uv` sends it as the password of basic authentication, which GitHub and GitLab accept.",
+								Optional:    true,
+								Sensitive:   true,
+								Validators: []validator.String{
+									stringvalidator.ConflictsWith(
+										path.MatchRelative().AtParent().AtName("username"),
+										path.MatchRelative().AtParent().AtName("password"),
+									),
+								},
+							},
+							"github_app": schema.SingleNestedAttribute{
+								Description: "GitHub App installation to authenticate as. A short-lived installation token is requested for every deployment instead of using `username` and `password` or `token`.",
+								Attributes: map[string]schema.Attribute{
+									"app_id": schema.Int64Attribute{
+										Description: "ID of the GitHub App.",
+										Required:    true,
+									},
+									"installation_id": schema.Int64Attribute{
+										Description: "ID of the app's installation with access to the repository.",
+										Required:    true,
+									},
+									"private_key": schema.StringAttribute{
+										Description: "PEM encoded private key of the GitHub App.",
+										Required:    true,
+										Sensitive:   true,
+									},
+									"api_url": schema.StringAttribute{
+										Description: "GitHub API URL, for GitHub Enterprise Server. Defaults to `" + git.DefaultGitHubAPIURL + "`.",
+										Optional:    true,
+										Validators: []validator.String{
+											validators.URLScheme("http", "https"),
+										},
+									},
+								},
+								Optional: true,
+								Validators: []validator.Object{
+									objectvalidator.ConflictsWith(
+										path.MatchRelative().AtParent().AtName("username"),
+										path.MatchRelative().AtParent().AtName("password"),
+										path.MatchRelative().AtParent().AtName("token"),
+									),
+								},
+							},
+						},
+						Optional: true,
+					},
+					"verify_signature": schema.SingleNestedAttribute{
+						Description: "Only deploy commits signed by trusted keys. The deployment fails if the commit is unsigned or signed by any other key.",
+						Attributes: map[string]schema.Attribute{
+							"gpg_keys": schema.StringAttribute{
+								Description: "Armored GPG public keys trusted to sign commits, as exported by `gpg --armor --export`.",
+								Optional:    true,
+								Validators: []validator.String{
+									stringvalidator.AtLeastOneOf(path.MatchRelative().AtParent().AtName("ssh_allowed_signers")),
+								},
+							},
+							"ssh_allowed_signers": schema.StringAttribute{
+								Description: "SSH public keys trusted to sign commits, in the allowed signers format of `ssh-keygen`, e.g. `dev@example.com ssh-ed25519 AAAA...`.",
+								Optional:    true,
+							},
+							"tag": schema.StringAttribute{
+								Description: "Annotated tag that must be signed by a trusted key too and point at the deployed commit.",
+								Optional:    true,
+							},
 						},
 						Optional: true,
 					},
 				},
 				Required: true,
 			},
+			"health_check": schema.SingleNestedAttribute{
+				Description: "Health check polled after every deployment. The apply fails if the app does not answer with the expected status in time.",
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Description: "Base URL to poll. Defaults to the app's `endpoint_url`. The fal API key is only sent to URLs on `fal.run` and its subdomains, set `headers` to authenticate elsewhere.",
+						Optional:    true,
+						Validators: []validator.String{
+							validators.URLScheme("http", "https"),
+						},
+					},
+					"path": schema.StringAttribute{
+						Description: "Path of the health endpoint, relative to the base URL.",
+						Required:    true,
+					},
+					"expected_status": schema.Int64Attribute{
+						Description: fmt.Sprintf("HTTP status of a healthy response. Defaults to `%d`.", defaultHealthCheckStatus),
+						Optional:    true,
+						Computed:    true,
+						Validators: []validator.Int64{
+							int64validator.Between(100, 599),
+						},
+						Default: int64default.StaticInt64(defaultHealthCheckStatus),
+					},
+					"payload": schema.StringAttribute{
+						Description: "JSON payload to send. The health endpoint is called with POST when set and with GET otherwise.",
+						Optional:    true,
+					},
+					"timeout": schema.Int64Attribute{
+						Description: fmt.Sprintf("Timeout of a single request in seconds. Defaults to `%d`.", defaultHealthCheckTimeout),
+						Optional:    true,
+						Computed:    true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+						Default: int64default.StaticInt64(defaultHealthCheckTimeout),
+					},
+					"retries": schema.Int64Attribute{
+						Description: fmt.Sprintf("Number of retries after the first failed request. Defaults to `%d`.", defaultHealthCheckRetries),
+						Optional:    true,
+						Computed:    true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+						Default: int64default.StaticInt64(defaultHealthCheckRetries),
+					},
+					"interval": schema.Int64Attribute{
+						Description: fmt.Sprintf("Seconds to wait between requests. Defaults to `%d`.", defaultHealthCheckInterval),
+						Optional:    true,
+						Computed:    true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+						Default: int64default.StaticInt64(defaultHealthCheckInterval),
+					},
+					"headers": schema.MapAttribute{
+						Description: "Headers to send with every request, e.g. the `Authorization` header a `url` outside of fal requires. They replace the headers the provider sets.",
+						ElementType: types.StringType,
+						Optional:    true,
+						Sensitive:   true,
+					},
+				},
+				Optional: true,
+			},
+			"lockfile_mode": schema.StringAttribute{
+				MarkdownDescription: "How the provider's `runner` treats the project's lockfile, `uv.lock` or `poetry.lock`. Available values: `frozen` installs what it pins without checking it against `pyproject.toml`, `locked` fails the deployment if it is missing or out of date, `ignore` resolves the dependencies again. Without it, the lockfile is used if it is up to date and updated otherwise. `frozen` is only supported by the `uv` runner, the `pip` runner only supports `ignore` and the `fal` runner none.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(runner.LockfileModes...),
+				},
+			},
+			"lockfile_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 of the project's lockfile in the commit to deploy, read while planning, so a changed lockfile shows up as an update. Unless `git.branch` pins a commit, it is only known after the deployment, as the branch may move on in between. Unset if the project or the `runner` has none.",
+				Computed:            true,
+			},
+			"python_index": schema.SingleNestedAttribute{
+				Description: "Python package indexes the app's dependencies are installed from. Replaces the provider's `python_index` as a whole.",
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Description: "URL of the index replacing PyPI.",
+						Optional:    true,
+						Validators: []validator.String{
+							validators.URLScheme("http", "https"),
+						},
+					},
+					"extra_urls": schema.ListAttribute{
+						Description: "URLs of indexes searched before `url`, in order.",
+						ElementType: types.StringType,
+						Optional:    true,
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(validators.URLScheme("http", "https")),
+						},
+					},
+					"username": schema.StringAttribute{
+						Description: "Username for the indexes.",
+						Optional:    true,
+					},
+					"password": schema.StringAttribute{
+						Description: "Password or token for the indexes.",
+						Optional:    true,
+						Sensitive:   true,
+					},
+				},
+				Optional: true,
+			},
+			"rollback_on_failure": schema.BoolAttribute{
+				MarkdownDescription: "Re-activate the previous revision when an update fails to deploy or does not pass its health check. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
 			"created_at": schema.StringAttribute{
 				MarkdownDescription: "The timestamp for when the app was created",
 				Computed:            true,
@@ -207,6 +543,196 @@ func (r *AppResource) Configure(ctx context.Context, req resource.ConfigureReque
 	r.client = _client
 }
 
+func (r *AppResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AppResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateGit(ctx, &data, &resp.Diagnostics)
+}
+
+// validateGit checks the git transport settings at plan time rather than
+// mid-deployment.
+func validateGit(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) {
+	if data.Git.IsUnknown() {
+		return
+	}
+	g := gitFromResourceModel(ctx, data).git
+
+	if !g.URL.IsUnknown() {
+		u, err := git.ParseURL(g.URL.ValueString())
+		if err == nil && u.Scheme == "http" && (g.HTTP == nil || !g.HTTP.InsecureHTTPAllowed.ValueBool()) {
+			diags.AddAttributeError(
+				path.Root("git").AtName("url"),
+				"Insecure Git URL",
+				"Plain HTTP Git URLs are rejected unless `http.allow_insecure_http` is set. Use an HTTPS URL where possible.",
+			)
+		}
+	}
+
+	if !g.Path.IsUnknown() && !g.SparsePaths.IsNull() && !g.SparsePaths.IsUnknown() {
+		validateSparsePaths(ctx, g, diags)
+	}
+
+	if v := g.VerifySignature; v != nil && !v.GPGKeys.IsUnknown() && !v.SSHAllowedSigners.IsUnknown() {
+		opts := &git.SignatureOpts{GPGKeys: v.GPGKeys.ValueString(), SSHAllowedSigners: v.SSHAllowedSigners.ValueString()}
+		if err := opts.Validate(); err != nil {
+			diags.AddAttributeError(
+				path.Root("git").AtName("verify_signature"),
+				"Invalid Signing Keys",
+				"`gpg_keys` must be armored GPG public keys and `ssh_allowed_signers` in the allowed signers format of `ssh-keygen`: "+err.Error(),
+			)
+		}
+	}
+
+	if g.HTTP == nil {
+		return
+	}
+
+	if g.HTTP.InsecureSkipVerify.ValueBool() {
+		diags.AddAttributeWarning(
+			path.Root("git").AtName("http").AtName("insecure_skip_tls_verify"),
+			"TLS Verification Disabled",
+			"The Git server's TLS certificate is not verified, so the source code deployed can be tampered with in transit. Prefer `certificate_authority` for self-signed certificates.",
+		)
+	}
+
+	cert, key := g.HTTP.ClientCertificate, g.HTTP.ClientKey
+	if cert.IsNull() || cert.IsUnknown() || key.IsNull() || key.IsUnknown() {
+		return
+	}
+
+	if _, err := git.ParseClientCertificate([]byte(cert.ValueString()), []byte(key.ValueString())); err != nil {
+		diags.AddAttributeError(
+			path.Root("git").AtName("http").AtName("client_certificate"),
+			"Invalid Client Certificate",
+			"`client_certificate` and `client_key` must be a PEM encoded certificate and its private key: "+err.Error(),
+		)
+	}
+}
+
+// validateSparsePaths checks that sparse_paths stay inside the repository and
+// include the project path.
+func validateSparsePaths(ctx context.Context, g *Git, diags *diag.Diagnostics) {
+	var paths []types.String
+	diags.Append(g.SparsePaths.ElementsAs(ctx, &paths, false)...)
+
+	project := filepath.Clean(g.Path.ValueString())
+	included := project == "."
+	for i, p := range paths {
+		if p.IsUnknown() {
+			// the project path may be in the unknown one
+			included = true
+			continue
+		}
+		dir := filepath.Clean(p.ValueString())
+		if !filepath.IsLocal(dir) {
+			diags.AddAttributeError(
+				path.Root("git").AtName("sparse_paths").AtListIndex(i),
+				"Invalid Sparse Path",
+				fmt.Sprintf("Sparse path %s must be relative to the repository root and stay inside it.", p.ValueString()),
+			)
+			continue
+		}
+		if rel, err := filepath.Rel(dir, project); err == nil && filepath.IsLocal(rel) {
+			included = true
+		}
+	}
+
+	if !included {
+		diags.AddAttributeError(
+			path.Root("git").AtName("sparse_paths"),
+			"Project Path Not Checked Out",
+			fmt.Sprintf("`path` %s must be inside one of `sparse_paths`, otherwise the project is not checked out.", g.Path.ValueString()),
+		)
+	}
+}
+
+// ModifyPlan reads the hash of uv.lock from the commit to deploy, so a
+// changed lockfile plans an update.
+func (r *AppResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing is deployed when destroying
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data AppResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || !fullyKnown(ctx, data.PythonIndex) || data.LockfileMode.IsUnknown() {
+		return
+	}
+
+	// the provider's runner is only known from here on, settings it does not
+	// support are rejected now rather than when deploying
+	err := r.client.ValidateRunner(&fal.DeployOpts{
+		PythonIndex:  pythonIndexFromResourceModel(ctx, &data, &resp.Diagnostics),
+		LockfileMode: runner.LockfileMode(data.LockfileMode.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Unsupported Runner Configuration", err.Error())
+	}
+	if resp.Diagnostics.HasError() || !fullyKnown(ctx, data.Git) {
+		return
+	}
+
+	// the deployment reports why the repository cannot be read, until then
+	// the hash is left as planned
+	var diags diag.Diagnostics
+	gitClient, repo, opts := r.deployOpts(ctx, &data, &diags)
+	if diags.HasError() {
+		return
+	}
+	hash, err := r.client.LockfileHash(ctx, gitClient, repo, opts)
+	if err != nil {
+		return
+	}
+
+	var prior AppResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	planned := lockfileHashValue(hash)
+	changed := req.State.Raw.IsNull() || !planned.Equal(prior.LockfileHash)
+
+	// A branch may move on between planning and deploying it, so unless it
+	// is pinned to a commit, the hash is only known once a deployment read
+	// it. It keeps its value when nothing is deployed.
+	deployed := changed || !req.Plan.Raw.Equal(req.State.Raw)
+	if deployed && !git.IsCommitHash(opts.Branch) {
+		planned = types.StringUnknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("lockfile_hash"), planned)...)
+
+	// Terraform only plans new values for computed attributes when the
+	// configuration changed, a new lockfile alone deploys a new revision too
+	if req.State.Raw.IsNull() || !changed {
+		return
+	}
+	for _, name := range []string{"revision_id", "updated_at"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+}
+
+func fullyKnown(ctx context.Context, v attr.Value) bool {
+	tv, err := v.ToTerraformValue(ctx)
+	return err == nil && tv.IsFullyKnown()
+}
+
+func lockfileHashValue(hash string) types.String {
+	if hash == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(hash)
+}
+
 func (r *AppResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data AppResourceModel
 
@@ -222,6 +748,10 @@ func (r *AppResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
+	// The app is deployed from here on, so it is saved even if it turns out
+	// unhealthy and Terraform taints it.
+	r.checkHealth(ctx, &data, &resp.Diagnostics)
+
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -252,10 +782,11 @@ func (r *AppResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 }
 
 func (r *AppResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data AppResourceModel
+	var data, prior AppResourceModel
 
-	// Read Terraform plan data into the model
+	// Read Terraform plan and prior state data into the models
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -263,6 +794,14 @@ func (r *AppResource) Update(ctx context.Context, req resource.UpdateRequest, re
 
 	r.deployApp(ctx, &data, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
+		r.rollback(ctx, &data, &prior, &resp.Diagnostics)
+		return
+	}
+
+	// Without setting a new state, Terraform keeps the prior one, which
+	// describes the app again once it is rolled back.
+	r.checkHealth(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() && r.rollback(ctx, &data, &prior, &resp.Diagnostics) {
 		return
 	}
 
@@ -290,19 +829,9 @@ func (r *AppResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 }
 
 func (r *AppResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	var data AppResourceModel
-	data.Name = types.StringValue(req.ID)
-
-	r.readApp(ctx, &data, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	if data.Name.IsNull() && data.RevisionID.IsNull() {
-		return
-	}
-
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	// Read resolves the rest of the app from its name and drops it from state
+	// if it does not exist.
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
 }
 
 func (r *AppResource) readApp(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) {
@@ -341,33 +870,122 @@ func (r *AppResource) readApp(ctx context.Context, data *AppResourceModel, diags
 	if app.AuthMode != "" {
 		data.AuthMode = types.StringValue(strings.ToLower(app.AuthMode))
 	}
+
+	// fal only reports the owner on newer versions, fall back to the one the
+	// app was deployed under
+	owner := app.Owner
+	if owner == "" {
+		owner = fal.OwnerFromEndpoint(data.EndpointURL.ValueString())
+	}
+	if owner != "" {
+		setEndpoints(data, fal.EndpointsFor(owner, app.Alias))
+	} else {
+		setEndpoints(data, nil)
+	}
 }
 
-func (r *AppResource) deployApp(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) {
+func setEndpoints(data *AppResourceModel, e *fal.Endpoints) {
+	if e == nil {
+		data.EndpointURL = types.StringNull()
+		data.QueueURL = types.StringNull()
+		data.WsURL = types.StringNull()
+		return
+	}
+	data.EndpointURL = types.StringValue(e.Sync)
+	data.QueueURL = types.StringValue(e.Queue)
+	data.WsURL = types.StringValue(e.WebSocket)
+}
+
+// deployOpts returns the git client, repository and options the app of data
+// is deployed with.
+func (r *AppResource) deployOpts(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) (*git.Client, string, *fal.DeployOpts) {
 	gd := gitFromResourceModel(ctx, data)
 
-	git, err := gd.Client()
+	gitClient, err := gd.Client(ctx, r.client.Proxy())
 	if err != nil {
 		diags.AddError("Client Error", "Unable to get git client, got error: "+err.Error())
-		return
+		return nil, "", nil
 	}
 
 	repoURL, err := gd.RepositoryURL()
 	if err != nil {
 		diags.AddError("Client Error", "Unable to get repository url, got error: "+err.Error())
+		return nil, "", nil
+	}
+	index := pythonIndexFromResourceModel(ctx, data, diags)
+	if diags.HasError() {
+		return nil, "", nil
+	}
+
+	return gitClient, repoURL.String(), &fal.DeployOpts{
+		Branch:       gd.git.Branch.ValueString(),
+		Path:         gd.git.Path.ValueString(),
+		Depth:        gd.Depth(),
+		SparsePaths:  gd.SparsePaths(ctx),
+		Submodules:   gd.git.Submodules.ValueBool(),
+		LFS:          gd.git.LFS.ValueBool(),
+		Verify:       gd.SignatureOpts(),
+		PythonIndex:  index,
+		LockfileMode: runner.LockfileMode(data.LockfileMode.ValueString()),
+		AppName:      data.Name.ValueString(),
+		Entrypoint:   data.Entrypoint.ValueString(),
+		Strategy:     fal.DeployStrategy(data.Strategy.ValueString()),
+		AuthMode:     fal.AuthMode(data.AuthMode.ValueString()),
+	}
+}
+
+// pythonIndexFromResourceModel returns the package indexes the app sets, nil
+// if it sets none.
+func pythonIndexFromResourceModel(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) *runner.PythonIndex {
+	if data.PythonIndex.IsNull() || data.PythonIndex.IsUnknown() {
+		return nil
+	}
+	var index falPythonIndexModel
+	diags.Append(data.PythonIndex.As(ctx, &index, basetypes.ObjectAsOptions{})...)
+	return index.pythonIndex(ctx)
+}
+
+func (r *AppResource) deployApp(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) {
+	gitClient, repo, opts := r.deployOpts(ctx, data, diags)
+	if diags.HasError() {
+		return
+	}
+
+	res, err := r.client.Deploy(ctx, gitClient, repo, opts)
+	var hostKeyErr *git.HostKeyError
+	if errors.As(err, &hostKeyErr) {
+		diags.AddAttributeError(
+			path.Root("git").AtName("ssh"),
+			"Host Key Verification Failed",
+			fmt.Sprintf("The host key of %s could not be verified: %s. The server presented a key with fingerprint %s; check known_hosts and host_key_fingerprint.", hostKeyErr.Host, hostKeyErr.Reason, hostKeyErr.Fingerprint),
+		)
+		return
+	}
+	var signatureErr *git.SignatureError
+	if errors.As(err, &signatureErr) {
+		diags.AddAttributeError(
+			path.Root("git").AtName("verify_signature"),
+			"Signature Verification Failed",
+			fmt.Sprintf("The signature of %s could not be verified: %s. Only commits signed by a key in `gpg_keys` or `ssh_allowed_signers` are deployed.", signatureErr.Object, signatureErr.Reason),
+		)
 		return
 	}
-	res, err := r.client.Deploy(ctx, git, repoURL.String(), &fal.DeployOpts{
-		Entrypoint: data.Entrypoint.ValueString(),
-		Strategy:   fal.DeployStrategy(data.Strategy.ValueString()),
-		AuthMode:   fal.AuthMode(data.AuthMode.ValueString()),
-	})
 	if err != nil {
 		diags.AddError("Client Error", "Unable to deploy app, got error: "+err.Error())
 		return
 	}
+	for _, w := range res.Warnings {
+		diags.AddWarning("fal deploy warning", w)
+	}
+
 	data.Name = types.StringValue(res.FunctionName)
 	data.RevisionID = types.StringValue(res.Revision)
+	data.LockfileHash = lockfileHashValue(res.LockfileHash)
+	if e := res.AppEndpoints(); e != nil {
+		setEndpoints(data, e)
+	} else if data.EndpointURL.IsUnknown() {
+		setEndpoints(data, nil)
+	}
 
 	now := time.Now().Format(time.RFC3339)
 
@@ -377,3 +995,69 @@ func (r *AppResource) deployApp(ctx context.Context, data *AppResourceModel, dia
 
 	data.UpdatedAt = types.StringValue(now)
 }
+
+func (r *AppResource) checkHealth(ctx context.Context, data *AppResourceModel, diags *diag.Diagnostics) {
+	if data.HealthCheck.IsNull() || data.HealthCheck.IsUnknown() {
+		return
+	}
+
+	var hc HealthCheck
+	diags.Append(data.HealthCheck.As(ctx, &hc, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return
+	}
+
+	base := hc.URL.ValueString()
+	if base == "" {
+		base = data.EndpointURL.ValueString()
+	}
+	if base == "" {
+		diags.AddError("Health Check Failed", "The app's endpoint URL is unknown, set `health_check.url` to the URL the app is served on.")
+		return
+	}
+
+	headers := map[string]string{}
+	diags.Append(hc.Headers.ElementsAs(ctx, &headers, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	err := r.client.WaitHealthy(ctx, &fal.HealthCheckOpts{
+		URL:            strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(hc.Path.ValueString(), "/"),
+		Payload:        hc.Payload.ValueString(),
+		ExpectedStatus: int(hc.ExpectedStatus.ValueInt64()),
+		Timeout:        time.Duration(hc.Timeout.ValueInt64()) * time.Second,
+		Retries:        int(hc.Retries.ValueInt64()),
+		Interval:       time.Duration(hc.Interval.ValueInt64()) * time.Second,
+		Headers:        headers,
+	})
+	if err != nil {
+		diags.AddError(
+			"Health Check Failed",
+			fmt.Sprintf("Revision %s of app %s is unhealthy: %s", data.RevisionID.ValueString(), data.Name.ValueString(), err.Error()),
+		)
+	}
+}
+
+// rollback re-activates the prior revision after a failed update if the app
+// asks for it, and reports whether the app was rolled back.
+func (r *AppResource) rollback(ctx context.Context, data, prior *AppResourceModel, diags *diag.Diagnostics) bool {
+	if !data.RollbackOnFailure.ValueBool() {
+		return false
+	}
+
+	name := prior.Name.ValueString()
+	revision := prior.RevisionID.ValueString()
+	if name == "" || revision == "" {
+		diags.AddError("Rollback Failed", "The previous revision of the app is unknown.")
+		return false
+	}
+
+	if err := r.client.SetRevision(ctx, name, revision); err != nil {
+		diags.AddError("Rollback Failed", fmt.Sprintf("Unable to roll app %s back to revision %s, got error: %s", name, revision, err.Error()))
+		return false
+	}
+
+	diags.AddWarning("Rolled Back", fmt.Sprintf("The update failed and app %s was rolled back to revision %s.", name, revision))
+	return true
+}
@@ -0,0 +1,248 @@
+package fal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/fal"
+	"github.com/fal-ai/terraform-provider-fal/internal/validators"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource                = &AppAliasResource{}
+	_ resource.ResourceWithImportState = &AppAliasResource{}
+)
+
+func NewAppAliasResource() resource.Resource {
+	return &AppAliasResource{}
+}
+
+// AppAliasResource defines the resource implementation.
+type AppAliasResource struct {
+	client *fal.Client
+}
+
+// AppAliasResourceModel describes the resource data model.
+type AppAliasResourceModel struct {
+	Name        types.String `tfsdk:"name"`
+	RevisionID  types.String `tfsdk:"revision_id"`
+	EndpointURL types.String `tfsdk:"endpoint_url"`
+	QueueURL    types.String `tfsdk:"queue_url"`
+	WsURL       types.String `tfsdk:"ws_url"`
+}
+
+func (r *AppAliasResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_app_alias"
+}
+
+func (r *AppAliasResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The fal_app_alias resource points an alias at a specific revision of a fal app. Unlike the alias managed by `fal_app`, it only moves when `revision_id` is changed.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The alias' name",
+				Required:            true,
+				Validators: []validator.String{
+					validators.AppName(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"revision_id": schema.StringAttribute{
+				MarkdownDescription: "The revision id the alias points at",
+				Required:            true,
+			},
+			"endpoint_url": schema.StringAttribute{
+				MarkdownDescription: "The alias' synchronous endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"queue_url": schema.StringAttribute{
+				MarkdownDescription: "The alias' queue endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ws_url": schema.StringAttribute{
+				MarkdownDescription: "The alias' websocket endpoint URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AppAliasResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*fal.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *fal.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+	r.client = client
+}
+
+func (r *AppAliasResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AppAliasResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.setRevision(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppAliasResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AppAliasResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found := r.readAlias(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The alias was deleted outside of Terraform
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppAliasResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AppAliasResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.setRevision(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppAliasResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AppAliasResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Delete(ctx, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", "Unable to delete app alias, got error: "+err.Error())
+		return
+	}
+}
+
+func (r *AppAliasResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
+}
+
+func (r *AppAliasResource) setRevision(ctx context.Context, data *AppAliasResourceModel, diags *diag.Diagnostics) {
+	name := data.Name.ValueString()
+	revision := data.RevisionID.ValueString()
+
+	if err := r.client.SetRevision(ctx, name, revision); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to point alias %s at revision %s, got error: %s", name, revision, err.Error()))
+		return
+	}
+
+	if !r.readAlias(ctx, data, diags) && !diags.HasError() {
+		diags.AddError("Client Error", fmt.Sprintf("Alias %s was not found after pointing it at revision %s.", name, revision))
+	}
+}
+
+// readAlias refreshes data from fal's app list and reports whether the alias
+// exists.
+func (r *AppAliasResource) readAlias(ctx context.Context, data *AppAliasResourceModel, diags *diag.Diagnostics) bool {
+	name := data.Name.ValueString()
+
+	apps, err := r.client.List(ctx)
+	if err != nil {
+		diags.AddError("Client Error", "Unable to read apps, got error: "+err.Error())
+		return false
+	}
+
+	var app *fal.App
+	for _, a := range apps {
+		if a.Alias == name {
+			app = a
+			break
+		}
+	}
+	if app == nil {
+		return false
+	}
+
+	data.RevisionID = types.StringValue(app.Revision)
+
+	owner := app.Owner
+	if owner == "" {
+		owner = fal.OwnerFromEndpoint(data.EndpointURL.ValueString())
+	}
+	if owner == "" {
+		data.EndpointURL = types.StringNull()
+		data.QueueURL = types.StringNull()
+		data.WsURL = types.StringNull()
+		return true
+	}
+
+	e := fal.EndpointsFor(owner, name)
+	data.EndpointURL = types.StringValue(e.Sync)
+	data.QueueURL = types.StringValue(e.Queue)
+	data.WsURL = types.StringValue(e.WebSocket)
+	return true
+}
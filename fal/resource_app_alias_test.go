@@ -0,0 +1,189 @@
+package fal
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+const (
+	testAccAppAliasResourceName = "fal_app_alias.test"
+	testAccAppAliasName         = "demo-prod"
+)
+
+func testAccAppAliasConfig(url, authMode, revisionID string) string {
+	return testAccAppConfig(url, authMode) + fmt.Sprintf(`
+resource "fal_app_alias" "test" {
+  name        = %[1]q
+  revision_id = %[2]s
+}
+`, testAccAppAliasName, revisionID)
+}
+
+func testAccCheckAppAliasRevision(t *testing.T, env *acctest.Env, revision string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		alias := env.State(t).App(testAccAppAliasName)
+		if alias == nil {
+			return fmt.Errorf("alias %q does not exist", testAccAppAliasName)
+		}
+		if alias.Revision != revision {
+			return fmt.Errorf("expected alias %q at revision %q, got %q", testAccAppAliasName, revision, alias.Revision)
+		}
+		return nil
+	}
+}
+
+func testAccCheckAppAliasDestroyed(t *testing.T, env *acctest.Env) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if alias := env.State(t).App(testAccAppAliasName); alias != nil {
+			return fmt.Errorf("alias %q still exists at revision %q", alias.Alias, alias.Revision)
+		}
+		return testAccCheckAppDestroyed(t, env)(s)
+	}
+}
+
+func TestAccAppAliasResource(t *testing.T) {
+	env := acctest.Setup(t)
+	srv := acctest.NewHTTPServer(t, testAccAppRepositories(t))
+	url := srv.RepoURL(testAccAppRepoPath)
+
+	const (
+		rev1 = "00000000-0000-4000-8000-000000000001"
+		rev2 = "00000000-0000-4000-8000-000000000002"
+	)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckAppAliasDestroyed(t, env),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccAppAliasConfig(url, "private", "fal_app.test.revision_id"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "name", testAccAppAliasName),
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "revision_id", rev1),
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "endpoint_url", "https://fal.run/"+acctest.Owner+"/"+testAccAppAliasName),
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "queue_url", "https://queue.fal.run/"+acctest.Owner+"/"+testAccAppAliasName),
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "ws_url", "wss://ws.fal.run/"+acctest.Owner+"/"+testAccAppAliasName),
+					testAccCheckAppAliasRevision(t, env, rev1),
+				),
+			},
+			// A new deployment of the app does not move a pinned alias.
+			{
+				Config: testAccAppAliasConfig(url, "public", fmt.Sprintf("%q", rev1)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(testAccAppAliasResourceName, plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testAccAppResourceName, "revision_id", rev2),
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "revision_id", rev1),
+					testAccCheckAppDeployed(t, env, "public"),
+					testAccCheckAppAliasRevision(t, env, rev1),
+				),
+			},
+			// Promote the new revision.
+			{
+				Config: testAccAppAliasConfig(url, "public", "fal_app.test.revision_id"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(testAccAppAliasResourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(testAccAppAliasResourceName, "revision_id", rev2),
+					testAccCheckAppAliasRevision(t, env, rev2),
+				),
+			},
+			// The alias was moved outside of Terraform and is moved back.
+			{
+				PreConfig: func() {
+					env.UpdateState(t, func(s *acctest.State) {
+						s.App(testAccAppAliasName).Revision = rev1
+					})
+				},
+				Config: testAccAppAliasConfig(url, "public", "fal_app.test.revision_id"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(testAccAppAliasResourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckAppAliasRevision(t, env, rev2),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         testAccAppAliasResourceName,
+				ImportState:                          true,
+				ImportStateId:                        testAccAppAliasName,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+		},
+	})
+}
+
+func TestAccAppAliasResource_deleteFailure(t *testing.T) {
+	env := acctest.Setup(t)
+	srv := acctest.NewHTTPServer(t, testAccAppRepositories(t))
+	url := srv.RepoURL(testAccAppRepoPath)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckAppAliasDestroyed(t, env),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAppAliasConfig(url, "private", "fal_app.test.revision_id"),
+			},
+			// fal fails to delete the alias, which is reported and kept.
+			{
+				PreConfig: func() {
+					env.UpdateState(t, func(s *acctest.State) {
+						s.Failures = map[string]string{"apps delete": "permission denied"}
+					})
+				},
+				Config:      testAccAppConfig(url, "private"),
+				ExpectError: regexp.MustCompile(`permission denied`),
+			},
+			{
+				PreConfig: func() {
+					if env.State(t).App(testAccAppAliasName) == nil {
+						t.Fatalf("alias %q was removed although fal failed to delete it", testAccAppAliasName)
+					}
+					env.UpdateState(t, func(s *acctest.State) {
+						s.Failures = nil
+					})
+				},
+				Config: testAccAppConfig(url, "private"),
+				Check: func(s *terraform.State) error {
+					if alias := env.State(t).App(testAccAppAliasName); alias != nil {
+						return fmt.Errorf("alias %q still exists", testAccAppAliasName)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccAppAliasResource_unknownRevision(t *testing.T) {
+	env := acctest.Setup(t)
+	srv := acctest.NewHTTPServer(t, testAccAppRepositories(t))
+	url := srv.RepoURL(testAccAppRepoPath)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckAppAliasDestroyed(t, env),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccAppAliasConfig(url, "private", `"00000000-0000-4000-8000-999999999999"`),
+				ExpectError: regexp.MustCompile(`Unable to point alias demo-prod at revision`),
+			},
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/fal-ai/terraform-provider-fal/internal/git"
+	"github.com/go-git/go-git/v6/plumbing/transport"
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
 	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
@@ -25,22 +26,36 @@ func gitFromResourceModel(ctx context.Context, data *AppResourceModel) *gitData
 	}
 }
 
-func (ard *gitData) Client() (*git.Client, error) {
+func (ard *gitData) Client(ctx context.Context, proxy *git.Proxy) (*git.Client, error) {
 	authOpts, err := getAuthOpts(ard.git)
 	if err != nil {
 		return nil, err
 	}
+	authOpts.Proxy = proxy
 
-	if ard.git.HTTP != nil && ard.git.HTTP.InsecureHTTPAllowed.ValueBool() {
+	if ard.git.HTTP != nil && ard.git.HTTP.InsecureSkipVerify.ValueBool() {
 		authOpts.InsecureSkipTLS = true
 	}
 
 	client := git.New(authOpts)
+
+	if ard.git.HTTP != nil && ard.git.HTTP.GitHubApp != nil {
+		app := ard.git.HTTP.GitHubApp
+		err := client.AuthenticateGitHubApp(ctx, &git.GitHubApp{
+			APIURL:         app.APIURL.ValueString(),
+			AppID:          app.AppID.ValueInt64(),
+			InstallationID: app.InstallationID.ValueInt64(),
+			PrivateKey:     []byte(app.PrivateKey.ValueString()),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not authenticate as github app installation: %w", err)
+		}
+	}
 	return client, nil
 }
 
 func (ard *gitData) RepositoryURL() (*url.URL, error) {
-	repositoryURL, err := url.Parse(ard.git.URL.ValueString())
+	repositoryURL, err := git.ParseURL(ard.git.URL.ValueString())
 	if err != nil {
 		return nil, fmt.Errorf("error parsing url: %w", err)
 	}
@@ -55,8 +70,37 @@ func (ard *gitData) RepositoryURL() (*url.URL, error) {
 	return repositoryURL, nil
 }
 
+// Depth returns the number of commits to clone, defaultGitDepth if unset.
+func (ard *gitData) Depth() int {
+	if ard.git.Depth.IsNull() {
+		return defaultGitDepth
+	}
+	return int(ard.git.Depth.ValueInt64())
+}
+
+// SparsePaths returns the directories to check out, nil for all of them.
+func (ard *gitData) SparsePaths(ctx context.Context) []string {
+	var paths []string
+	ard.git.SparsePaths.ElementsAs(ctx, &paths, false)
+	return paths
+}
+
+// SignatureOpts returns the keys the deployed commit must be signed with, or
+// nil if signatures are not verified.
+func (ard *gitData) SignatureOpts() *git.SignatureOpts {
+	v := ard.git.VerifySignature
+	if v == nil {
+		return nil
+	}
+	return &git.SignatureOpts{
+		GPGKeys:           v.GPGKeys.ValueString(),
+		SSHAllowedSigners: v.SSHAllowedSigners.ValueString(),
+		Tag:               v.Tag.ValueString(),
+	}
+}
+
 func getAuthOpts(g *Git) (*git.AuthOpts, error) {
-	u, err := url.Parse(g.URL.ValueString())
+	u, err := git.ParseURL(g.URL.ValueString())
 	if err != nil {
 		return nil, fmt.Errorf("error parsing url: %w", err)
 	}
@@ -66,37 +110,64 @@ func getAuthOpts(g *Git) (*git.AuthOpts, error) {
 			return &git.AuthOpts{}, nil
 		}
 		return &git.AuthOpts{
-			AuthMethod: &http.BasicAuth{
-				Username: g.HTTP.Username.ValueString(),
-				Password: g.HTTP.Password.ValueString(),
-			},
+			AuthMethod: httpAuthMethod(g.HTTP),
 		}, nil
 	case "https":
 		if g.HTTP == nil {
 			return &git.AuthOpts{}, nil
 		}
 		return &git.AuthOpts{
-			AuthMethod: &http.BasicAuth{
-				Username: g.HTTP.Username.ValueString(),
-				Password: g.HTTP.Password.ValueString(),
-			},
-			CABundle: []byte(g.HTTP.CertificateAuthority.ValueString()),
+			AuthMethod: httpAuthMethod(g.HTTP),
+			CABundle:   []byte(g.HTTP.CertificateAuthority.ValueString()),
+			ClientCert: []byte(g.HTTP.ClientCertificate.ValueString()),
+			ClientKey:  []byte(g.HTTP.ClientKey.ValueString()),
 		}, nil
 	case "ssh":
 		if g.SSH == nil {
 			return nil, fmt.Errorf("Git URL scheme is ssh but ssh configuration is empty")
 		}
+		hostKeyCallback, err := git.HostKeyCallback(g.SSH.KnownHosts.ValueString(), g.SSH.HostKeyFingerprint.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("could not handle ssh host key verification: %w", err)
+		}
+		if g.SSH.UseAgent.ValueBool() {
+			agentAuth, err := ssh.NewSSHAgentAuth(g.SSH.Username.ValueString())
+			if err != nil {
+				return nil, fmt.Errorf("could not handle ssh agent auth: %w", err)
+			}
+			agentAuth.HostKeyCallback = hostKeyCallback
+			return &git.AuthOpts{
+				AuthMethod: agentAuth,
+				KnownHosts: g.SSH.KnownHosts.ValueString(),
+			}, nil
+		}
 		if g.SSH.PrivateKey.ValueString() != "" {
 			sshKey, err := ssh.NewPublicKeys(g.SSH.Username.ValueString(), []byte(g.SSH.PrivateKey.ValueString()), g.SSH.Password.ValueString())
 			if err != nil {
 				return nil, fmt.Errorf("could not handle ssh key auth: %w", err)
 			}
+			sshKey.HostKeyCallback = hostKeyCallback
 			return &git.AuthOpts{
 				AuthMethod: sshKey,
+				PrivateKey: []byte(g.SSH.PrivateKey.ValueString()),
+				Passphrase: g.SSH.Password.ValueString(),
+				KnownHosts: g.SSH.KnownHosts.ValueString(),
 			}, nil
 		}
-		return nil, fmt.Errorf("ssh scheme cannot be used without private key")
+		return nil, fmt.Errorf("ssh scheme cannot be used without private key or use_agent")
 	default:
 		return nil, fmt.Errorf("scheme %q is not supported", u.Scheme)
 	}
 }
+
+// httpAuthMethod returns the static credentials of h. GitHub App
+// installation tokens replace them once exchanged.
+func httpAuthMethod(h *HTTP) transport.AuthMethod {
+	if h.Token.ValueString() != "" {
+		return &http.TokenAuth{Token: h.Token.ValueString()}
+	}
+	return &http.BasicAuth{
+		Username: h.Username.ValueString(),
+		Password: h.Password.ValueString(),
+	}
+}
@@ -0,0 +1,98 @@
+// Package acctest contains the harness used by the provider acceptance tests.
+//
+// The provider shells out to uv, pip, Poetry and the fal CLI, so the harness
+// replaces them with stubs backed by a local state file and serves fixture
+// repositories from in-process git servers. The stubs are the test binary itself: Main detects
+// when it is invoked under one of the stub names and emulates that command
+// instead of running the tests.
+package acctest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	// EnvState points the stub executables at their state file.
+	EnvState = "FAL_ACC_STATE"
+
+	// EnvLegacyCLI makes the fal stub behave like a CLI release without
+	// `fal deploy --output json` support.
+	EnvLegacyCLI = "FAL_ACC_LEGACY_CLI"
+
+	// FalKey is the key exported to the provider during acceptance tests.
+	FalKey = "acc-test-key"
+)
+
+var stubs = []string{"uv", "fal", "python3", "pip", "poetry"}
+
+// Main runs the stub named by os.Args[0] if there is one and the tests
+// otherwise. It is meant to be called from TestMain.
+func Main(m *testing.M) {
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	for _, stub := range stubs {
+		if name == stub {
+			os.Exit(runStub(name, os.Args[1:], os.Stdout, os.Stderr))
+		}
+	}
+	os.Exit(m.Run())
+}
+
+// Env is the environment prepared by Setup.
+type Env struct {
+	StatePath string
+}
+
+// Setup puts the stub executables on PATH and points them at a fresh state
+// file for the duration of the test.
+func Setup(t *testing.T) *Env {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("error locating test binary: %s", err)
+	}
+
+	bin := t.TempDir()
+	for _, name := range stubs {
+		if err := os.Symlink(exe, filepath.Join(bin, name)); err != nil {
+			t.Fatalf("error installing %s stub: %s", name, err)
+		}
+	}
+
+	env := &Env{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(EnvState, env.StatePath)
+	t.Setenv("FAL_KEY", FalKey)
+	return env
+}
+
+// State returns the current state of the stubs.
+func (e *Env) State(t *testing.T) *State {
+	t.Helper()
+
+	state, err := readState(e.StatePath)
+	if err != nil {
+		t.Fatalf("error reading stub state: %s", err)
+	}
+	return state
+}
+
+// UpdateState modifies the state of the stubs, e.g. to simulate drift made
+// outside of Terraform.
+func (e *Env) UpdateState(t *testing.T, fn func(*State)) {
+	t.Helper()
+
+	err := updateState(e.StatePath, func(s *State) error {
+		fn(s)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error updating stub state: %s", err)
+	}
+}
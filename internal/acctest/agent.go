@@ -0,0 +1,85 @@
+package acctest
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// StartSSHAgent serves an in-process ssh-agent holding privateKeys, OpenSSH
+// encoded like SSHServer.ClientKey, and points SSH_AUTH_SOCK at it.
+func StartSSHAgent(t testing.TB, privateKeys ...string) {
+	t.Helper()
+
+	keyring := agent.NewKeyring()
+	for _, k := range privateKeys {
+		key, err := ssh.ParseRawPrivateKey([]byte(k))
+		if err != nil {
+			t.Fatalf("error parsing agent key: %s", err)
+		}
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatalf("error adding agent key: %s", err)
+		}
+	}
+
+	// unix socket paths are limited to ~100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "agent")
+	if err != nil {
+		t.Fatalf("error creating agent dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("error listening: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := map[net.Conn]struct{}{}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := l.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				continue
+			}
+
+			mu.Lock()
+			conns[conn] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	// the provider keeps its agent connection open, so close it from here
+	t.Cleanup(func() {
+		l.Close()
+		mu.Lock()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	})
+
+	t.Setenv("SSH_AUTH_SOCK", sock)
+}
@@ -0,0 +1,466 @@
+package acctest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+)
+
+const (
+	// Owner is the account the fake fal CLI deploys apps under.
+	Owner = "fal-ai-test"
+)
+
+const (
+	defaultMachineType = "XS"
+)
+
+var (
+	appNameRe     = regexp.MustCompile(`app_name\s*=\s*["']([^"']+)["']`)
+	gitSourceRe   = regexp.MustCompile(`git\s*=\s*"([^"]+)"`)
+	machineTypeRe = regexp.MustCompile(`machine_type\s*=\s*["']([^"']+)["']`)
+)
+
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+func fail(code int, format string, args ...any) error {
+	return &exitError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// runStub executes the stub named name and returns the process exit code.
+func runStub(name string, args []string, stdout, stderr io.Writer) int {
+	statePath := os.Getenv(EnvState)
+	if statePath == "" {
+		fmt.Fprintf(stderr, "%s stub: %s is not set\n", name, EnvState)
+		return 2
+	}
+
+	dir, _ := os.Getwd()
+	env := map[string]string{}
+	for _, k := range RecordedEnv {
+		if v, ok := os.LookupEnv(k); ok {
+			env[k] = v
+		}
+	}
+	err := updateState(statePath, func(s *State) error {
+		s.Calls = append(s.Calls, Call{Name: name, Args: args, Dir: dir, Env: env})
+		return nil
+	})
+	if err == nil {
+		switch name {
+		case "uv":
+			err = runUv(args, stdout, stderr)
+		case "python3":
+			err = runPython(args)
+		case "pip":
+			err = runPip(args)
+		case "poetry":
+			err = runPoetry(args, stdout, stderr)
+		case "fal":
+			err = runFal(statePath, args, stdout)
+		default:
+			err = fail(2, "unknown stub %q", name)
+		}
+	}
+
+	var exit *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		fmt.Fprintln(stderr, "error: "+exit.msg)
+		return exit.code
+	default:
+		fmt.Fprintln(stderr, "error: "+err.Error())
+		return 1
+	}
+}
+
+func runUv(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fail(2, "uv: missing command")
+	}
+
+	switch args[0] {
+	case "init":
+		if _, err := os.Stat("pyproject.toml"); err == nil {
+			return nil
+		}
+		return os.WriteFile("pyproject.toml", []byte("[project]\nname = \"bare\"\nversion = \"0.0.0\"\n"), 0o644)
+	case "add", "venv":
+		return nil
+	case "sync":
+		pyproject, err := os.ReadFile("pyproject.toml")
+		if err != nil {
+			return fail(2, "No `pyproject.toml` found in current directory or any parent directory")
+		}
+		if err := checkLockfile(args[1:]); err != nil {
+			return err
+		}
+		if err := fetchGitSources(pyproject); err != nil {
+			return err
+		}
+		return relock(args[1:])
+	case "run":
+		// uv run syncs the project first, with the lockfile flags given
+		for len(args) > 1 && strings.HasPrefix(args[1], "--") {
+			if err := checkLockfile(args[1:2]); err != nil {
+				return err
+			}
+			args = append(args[:1], args[2:]...)
+		}
+		if len(args) < 2 {
+			return fail(2, "uv run: missing command")
+		}
+		return runCommand(args[1:], stdout, stderr)
+	default:
+		return fail(2, "uv: unsupported command %q", args[0])
+	}
+}
+
+// runCommand runs args like uv run and poetry run do.
+func runCommand(args []string, stdout, stderr io.Writer) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return &exitError{code: exit.ExitCode(), msg: fmt.Sprintf("%s exited with %d", args[0], exit.ExitCode())}
+		}
+		return err
+	}
+	return nil
+}
+
+// runPython only supports `python3 -m venv DIR`. The virtual environment
+// gets pip and fal stubs.
+func runPython(args []string) error {
+	if len(args) != 3 || args[0] != "-m" || args[1] != "venv" {
+		return fail(2, "python3: unsupported arguments %q", args)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	bin := filepath.Join(args[2], "bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		return err
+	}
+	for _, name := range []string{"pip", "fal"} {
+		if err := os.Symlink(exe, filepath.Join(bin, name)); err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func runPip(args []string) error {
+	if len(args) == 0 || args[0] != "install" {
+		return fail(2, "pip: unsupported arguments %q", args)
+	}
+	for i, arg := range args[1:] {
+		switch {
+		case arg == "-r" && i+2 < len(args):
+			if _, err := os.Stat(args[i+2]); err != nil {
+				return fail(1, "ERROR: Could not open requirements file: %s", err)
+			}
+		case arg == ".":
+			pyproject, err := os.ReadFile("pyproject.toml")
+			if err != nil {
+				return fail(1, "ERROR: Directory '.' is not installable. Neither 'setup.py' nor 'pyproject.toml' found.")
+			}
+			return fetchGitSources(pyproject)
+		}
+	}
+	return nil
+}
+
+func runPoetry(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fail(2, "poetry: missing command")
+	}
+
+	switch args[0] {
+	case "init":
+		if _, err := os.Stat("pyproject.toml"); err == nil {
+			return fail(1, "A pyproject.toml file with a project and/or a poetry section already exists.")
+		}
+		return os.WriteFile("pyproject.toml", []byte("[project]\nname = \"fal-runner\"\nversion = \"0.0.0\"\n"), 0o644)
+	case "add", "update":
+		return nil
+	case "install":
+		pyproject, err := os.ReadFile("pyproject.toml")
+		if err != nil {
+			return fail(1, "Poetry could not find a pyproject.toml file in %s or its parents", "the project")
+		}
+		return fetchGitSources(pyproject)
+	case "run":
+		if len(args) < 2 {
+			return fail(2, "poetry run: missing command")
+		}
+		return runCommand(args[1:], stdout, stderr)
+	default:
+		return fail(2, "poetry: unsupported command %q", args[0])
+	}
+}
+
+// checkLockfile fails like uv does when flags ask for the lockfile to be used
+// but there is none.
+func checkLockfile(flags []string) error {
+	for _, flag := range flags {
+		if flag != "--frozen" && flag != "--locked" {
+			continue
+		}
+		if _, err := os.Stat("uv.lock"); err != nil {
+			return fail(2, "Unable to find lockfile at `uv.lock`. To create a lockfile, run `uv lock` or `uv sync`.")
+		}
+	}
+	return nil
+}
+
+// relock rewrites uv.lock like uv sync does when it resolves the
+// dependencies again, unless flags ask for the lockfile to be used as is. uv
+// only does so when uv.lock is stale, the stub always does.
+func relock(flags []string) error {
+	if slices.Contains(flags, "--frozen") || slices.Contains(flags, "--locked") {
+		return nil
+	}
+	lock, err := os.ReadFile("uv.lock")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile("uv.lock", append(lock, "# resolved by uv sync\n"...), 0o644)
+}
+
+// fetchGitSources fetches the git dependencies of pyproject with the git
+// executable, which uv uses too, so they authenticate with the environment
+// uv was run with.
+func fetchGitSources(pyproject []byte) error {
+	for _, m := range gitSourceRe.FindAllSubmatch(pyproject, -1) {
+		out, err := exec.Command("git", "ls-remote", string(m[1])).CombinedOutput()
+		if err != nil {
+			return fail(1, "Failed to fetch: `%s`\n%s", m[1], out)
+		}
+	}
+	return nil
+}
+
+// deprecationWarning is written to stderr next to JSON documents, like the
+// warnings of the real fal CLI.
+const deprecationWarning = "DeprecationWarning: this fal version is deprecated, please upgrade"
+
+func runFal(statePath string, args []string, stdout io.Writer) error {
+	if os.Getenv("FAL_KEY") == "" {
+		return fail(1, "FAL_KEY is not set")
+	}
+	if len(args) >= 2 {
+		state, err := readState(statePath)
+		if err != nil {
+			return err
+		}
+		if msg, ok := state.Failures[args[0]+" "+args[1]]; ok {
+			return fail(1, "%s", msg)
+		}
+	}
+
+	switch {
+	case len(args) >= 2 && args[0] == "apps" && args[1] == "list":
+		return falList(statePath, stdout)
+	case len(args) >= 3 && args[0] == "apps" && args[1] == "delete":
+		return falDelete(statePath, args[2])
+	case len(args) >= 3 && args[0] == "apps" && args[1] == "list-rev":
+		return falListRevisions(statePath, args[2], stdout)
+	case len(args) >= 4 && args[0] == "apps" && args[1] == "set-rev":
+		return falSetRevision(statePath, args[2], args[3])
+	case len(args) >= 1 && args[0] == "deploy":
+		return falDeploy(statePath, args[1:], stdout)
+	default:
+		return fail(2, "fal: unsupported command %q", strings.Join(args, " "))
+	}
+}
+
+func falList(statePath string, stdout io.Writer) error {
+	state, err := readState(statePath)
+	if err != nil {
+		return err
+	}
+
+	type app struct {
+		Alias    string `json:"alias"`
+		Owner    string `json:"owner"`
+		Revision string `json:"revision"`
+		AuthMode string `json:"auth_mode"`
+	}
+	apps := make([]app, 0, len(state.Apps))
+	for _, a := range state.Apps {
+		apps = append(apps, app{
+			Alias:    a.Alias,
+			Owner:    Owner,
+			Revision: a.Revision,
+			AuthMode: strings.ToUpper(a.AuthMode),
+		})
+	}
+
+	fmt.Fprintln(os.Stderr, deprecationWarning)
+	return json.NewEncoder(stdout).Encode(map[string]any{"apps": apps})
+}
+
+func falDelete(statePath, alias string) error {
+	return updateState(statePath, func(s *State) error {
+		if !s.Remove(alias) {
+			return fail(1, "app %q not found", alias)
+		}
+		return nil
+	})
+}
+
+func falListRevisions(statePath, alias string, stdout io.Writer) error {
+	state, err := readState(statePath)
+	if err != nil {
+		return err
+	}
+
+	type revision struct {
+		Revision    string `json:"revision"`
+		CreatedAt   string `json:"created_at"`
+		MachineType string `json:"machine_type"`
+		GitCommit   string `json:"git_commit,omitempty"`
+	}
+	revisions := []revision{}
+	for _, r := range state.History {
+		if r.Alias != alias {
+			continue
+		}
+		revisions = append(revisions, revision{
+			Revision:    r.Revision,
+			CreatedAt:   r.CreatedAt,
+			MachineType: r.MachineType,
+			GitCommit:   r.GitCommit,
+		})
+	}
+
+	fmt.Fprintln(os.Stderr, deprecationWarning)
+	return json.NewEncoder(stdout).Encode(map[string]any{"revisions": revisions})
+}
+
+func falSetRevision(statePath, revision, alias string) error {
+	return updateState(statePath, func(s *State) error {
+		rev := s.Revision(revision)
+		if rev == nil {
+			return fail(1, "revision %q not found", revision)
+		}
+		app := *rev
+		app.Alias = alias
+		s.Remove(alias)
+		s.Apps = append(s.Apps, &app)
+		return nil
+	})
+}
+
+func falDeploy(statePath string, args []string, stdout io.Writer) error {
+	app := &App{
+		Strategy: "rolling",
+		AuthMode: "private",
+	}
+	var output string
+	appName := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "--strategy="):
+			app.Strategy = strings.TrimPrefix(arg, "--strategy=")
+		case strings.HasPrefix(arg, "--app-name="):
+			appName = strings.TrimPrefix(arg, "--app-name=")
+		case strings.HasPrefix(arg, "--auth="):
+			app.AuthMode = strings.TrimPrefix(arg, "--auth=")
+		case arg == "--output" && os.Getenv(EnvLegacyCLI) == "" && i+1 < len(args):
+			i++
+			output = args[i]
+		case strings.HasPrefix(arg, "-"):
+			return fail(2, "unrecognized arguments: %s", strings.Join(args[i:], " "))
+		default:
+			app.Entrypoint = arg
+		}
+	}
+	if app.Entrypoint == "" {
+		return fail(2, "fal deploy: missing entrypoint")
+	}
+
+	file, _, _ := strings.Cut(app.Entrypoint, "::")
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return fail(1, "could not load %s: %v", file, err)
+	}
+
+	app.CreatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	app.MachineType = defaultMachineType
+	if m := machineTypeRe.FindSubmatch(source); m != nil {
+		app.MachineType = string(m[1])
+	}
+	if repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true}); err == nil {
+		if head, err := repo.Head(); err == nil {
+			app.GitCommit = head.Hash().String()
+		}
+	}
+
+	if appName != "" {
+		app.Alias = appName
+	} else if m := appNameRe.FindSubmatch(source); m != nil {
+		app.Alias = string(m[1])
+	} else {
+		app.Alias = strings.ReplaceAll(strings.TrimSuffix(filepath.Base(file), ".py"), "_", "-")
+	}
+
+	err = updateState(statePath, func(s *State) error {
+		s.Revisions++
+		app.Revision = fmt.Sprintf("00000000-0000-4000-8000-%012d", s.Revisions)
+		s.Remove(app.Alias)
+		s.Apps = append(s.Apps, app)
+		rev := *app
+		s.History = append(s.History, &rev)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoints := []string{
+		fmt.Sprintf("https://fal.run/%s/%s", Owner, app.Alias),
+		fmt.Sprintf("https://queue.fal.run/%s/%s", Owner, app.Alias),
+	}
+
+	if output == "json" {
+		return json.NewEncoder(stdout).Encode(map[string]any{
+			"app_name":  app.Alias,
+			"revision":  app.Revision,
+			"endpoints": endpoints,
+		})
+	}
+
+	fmt.Fprintf(stdout, "Registered a new revision for function '%s'\n", app.Alias)
+	fmt.Fprintf(stdout, "(revision='%s').\n", app.Revision)
+	fmt.Fprintf(stdout, "Playground: https://fal.ai/models/%s/%s\n", Owner, app.Alias)
+	fmt.Fprintf(stdout, "Synchronous Endpoints:\n\t%s\n", endpoints[0])
+	fmt.Fprintf(stdout, "Asynchronous Endpoints (Recommended):\n\t%s\n", endpoints[1])
+	return nil
+}
@@ -0,0 +1,128 @@
+package acctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// GitHubAPI fakes the GitHub API endpoint that exchanges a GitHub App JWT for
+// an installation access token.
+type GitHubAPI struct {
+	*httptest.Server
+
+	AppID          int64
+	InstallationID int64
+	// PrivateKey is the PEM encoded private key of the app.
+	PrivateKey string
+	// Token is the installation token handed out.
+	Token string
+
+	// Requests counts the token requests served.
+	Requests atomic.Int64
+
+	key *rsa.PrivateKey
+}
+
+// NewGitHubAPI starts a fake GitHub API for a single app installation.
+func NewGitHubAPI(t testing.TB) *GitHubAPI {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating app key: %s", err)
+	}
+
+	s := &GitHubAPI{
+		AppID:          1234,
+		InstallationID: 5678,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})),
+		Token: "ghs_acctest",
+		key:   key,
+	}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *GitHubAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != fmt.Sprintf("/app/installations/%d/access_tokens", s.InstallationID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	if err := s.verify(jwt); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	s.Requests.Add(1)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":      s.Token,
+		"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+}
+
+// verify checks jwt is signed by the app's key and currently valid.
+func (s *GitHubAPI) verify(jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed jwt")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed jwt signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&s.key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		return fmt.Errorf("invalid jwt signature: %w", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("malformed jwt claims: %w", err)
+	}
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("malformed jwt claims: %w", err)
+	}
+
+	now := time.Now().Unix()
+	switch {
+	case claims.Iss != strconv.FormatInt(s.AppID, 10):
+		return fmt.Errorf("jwt issued by %q", claims.Iss)
+	case claims.Iat > now || claims.Exp < now:
+		return fmt.Errorf("jwt is not valid now")
+	case claims.Exp-claims.Iat > 10*60:
+		return fmt.Errorf("jwt is valid for too long")
+	}
+	return nil
+}
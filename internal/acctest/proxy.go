@@ -0,0 +1,105 @@
+package acctest
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Proxy is an HTTP forward proxy standing in for a corporate one. It resolves
+// hosts through Hosts only, so requests for made up hosts succeed only if
+// they go through it.
+type Proxy struct {
+	*httptest.Server
+
+	// Hosts maps the host:port requested through the proxy to the address
+	// it connects to.
+	Hosts map[string]string
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// NewProxy starts a forward proxy, supporting plain HTTP requests and CONNECT
+// tunnels.
+func NewProxy(t testing.TB) *Proxy {
+	t.Helper()
+
+	p := &Proxy{
+		Hosts:    map[string]string{},
+		requests: map[string]int{},
+	}
+	p.Server = httptest.NewServer(p)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// Requests returns how many requests or tunnels for host:port the proxy
+// served.
+func (p *Proxy) Requests(host string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests[host]
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if r.Method != http.MethodConnect {
+		host = r.URL.Host
+		if r.URL.Port() == "" {
+			host += ":80"
+		}
+	}
+	addr, ok := p.Hosts[host]
+	if !ok {
+		http.Error(w, "unknown host "+host, http.StatusBadGateway)
+		return
+	}
+
+	p.mu.Lock()
+	p.requests[host]++
+	p.mu.Unlock()
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, addr)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.URL.Host = addr
+	resp, err := http.DefaultTransport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, addr string) {
+	upstream, err := net.Dial("tcp", addr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	go io.Copy(upstream, buf)
+	io.Copy(conn, upstream)
+}
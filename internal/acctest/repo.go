@@ -0,0 +1,201 @@
+package acctest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v6/memfs"
+	"github.com/go-git/go-billy/v6/util"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/format/index"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// DefaultBranch is the branch fixture repositories are initialised with.
+const DefaultBranch = "main"
+
+// Repository is an in-memory git repository served by the test git servers.
+type Repository struct {
+	Storer *memory.Storage
+	Repo   *git.Repository
+
+	// LFS holds the Git LFS objects of the repository by their oid.
+	LFS map[string][]byte
+}
+
+// AppFiles returns a minimal fal project with a single app named name at
+// entrypoint.
+func AppFiles(entrypoint, name string) map[string]string {
+	return map[string]string{
+		"pyproject.toml": "[project]\nname = \"" + name + "\"\nversion = \"0.1.0\"\ndependencies = [\"fal\"]\n",
+		entrypoint:       "import fal\n\n\nclass App(fal.App):\n    app_name = \"" + name + "\"\n",
+	}
+}
+
+// NewRepository creates a repository on DefaultBranch with a single commit
+// containing files.
+func NewRepository(t testing.TB, files map[string]string) *Repository {
+	t.Helper()
+
+	storer := memory.NewStorage()
+	repo, err := git.Init(storer,
+		git.WithWorkTree(memfs.New()),
+		git.WithDefaultBranch(plumbing.NewBranchReferenceName(DefaultBranch)),
+	)
+	if err != nil {
+		t.Fatalf("error initialising fixture repository: %s", err)
+	}
+
+	r := &Repository{Storer: storer, Repo: repo, LFS: map[string][]byte{}}
+	r.Commit(t, "initial commit", files)
+	return r
+}
+
+// Commit writes files into the worktree and commits them, returning the new
+// commit hash.
+func (r *Repository) Commit(t testing.TB, message string, files map[string]string) string {
+	t.Helper()
+
+	return r.SignedCommit(t, message, files, nil)
+}
+
+// SignedCommit is like Commit but signs the commit with signer, if not nil.
+func (r *Repository) SignedCommit(t testing.TB, message string, files map[string]string, signer git.Signer) string {
+	t.Helper()
+
+	wt, err := r.Repo.Worktree()
+	if err != nil {
+		t.Fatalf("error opening fixture worktree: %s", err)
+	}
+
+	for path, content := range files {
+		if err := util.WriteFile(wt.Filesystem, path, []byte(content), 0o644); err != nil {
+			t.Fatalf("error writing fixture file %s: %s", path, err)
+		}
+		if _, err := wt.Add(path); err != nil {
+			t.Fatalf("error staging fixture file %s: %s", path, err)
+		}
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "fal acceptance",
+			Email: "acceptance@fal.ai",
+			When:  time.Now(),
+		},
+		Signer: signer,
+	})
+	if err != nil {
+		t.Fatalf("error committing fixture repository: %s", err)
+	}
+	return hash.String()
+}
+
+// Tag creates an annotated tag name pointing at commit, signed by signer if
+// not nil.
+func (r *Repository) Tag(t testing.TB, name, commit string, signer git.Signer) {
+	t.Helper()
+
+	tag := &object.Tag{
+		Name: name,
+		Tagger: object.Signature{
+			Name:  "fal acceptance",
+			Email: "acceptance@fal.ai",
+			When:  time.Now(),
+		},
+		Message:    name + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     plumbing.NewHash(commit),
+	}
+
+	// go-git only signs tags with GPG keys, so the tag object is built here
+	if signer != nil {
+		payload := &plumbing.MemoryObject{}
+		if err := tag.EncodeWithoutSignature(payload); err != nil {
+			t.Fatalf("error encoding fixture tag: %s", err)
+		}
+		reader, err := payload.Reader()
+		if err != nil {
+			t.Fatalf("error encoding fixture tag: %s", err)
+		}
+		sig, err := signer.Sign(reader)
+		if err != nil {
+			t.Fatalf("error signing fixture tag: %s", err)
+		}
+		tag.PGPSignature = string(sig)
+	}
+
+	obj := r.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		t.Fatalf("error encoding fixture tag: %s", err)
+	}
+	hash, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatalf("error storing fixture tag: %s", err)
+	}
+	if err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash)); err != nil {
+		t.Fatalf("error storing fixture tag reference: %s", err)
+	}
+}
+
+// Head returns the hash of the commit the default branch points at.
+func (r *Repository) Head(t testing.TB) string {
+	t.Helper()
+
+	ref, err := r.Repo.Head()
+	if err != nil {
+		t.Fatalf("error resolving fixture HEAD: %s", err)
+	}
+	return ref.Hash().String()
+}
+
+// LFSPointer stores content as a Git LFS object of the repository and returns
+// the pointer file to commit in its place.
+func (r *Repository) LFSPointer(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	oid := hex.EncodeToString(sum[:])
+	r.LFS[oid] = []byte(content)
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+}
+
+// AddSubmodule commits a submodule at path pointing at commit of the
+// repository at url, which may be relative to this one.
+func (r *Repository) AddSubmodule(t testing.TB, path, url, commit string) string {
+	t.Helper()
+
+	wt, err := r.Repo.Worktree()
+	if err != nil {
+		t.Fatalf("error opening fixture worktree: %s", err)
+	}
+
+	gitmodules := fmt.Sprintf("[submodule %q]\n\tpath = %s\n\turl = %s\n", path, path, url)
+	if err := util.WriteFile(wt.Filesystem, ".gitmodules", []byte(gitmodules), 0o644); err != nil {
+		t.Fatalf("error writing .gitmodules: %s", err)
+	}
+	if _, err := wt.Add(".gitmodules"); err != nil {
+		t.Fatalf("error staging .gitmodules: %s", err)
+	}
+
+	// a submodule is a gitlink entry in the index, which go-git cannot stage
+	// from a worktree
+	idx, err := r.Storer.Index()
+	if err != nil {
+		t.Fatalf("error reading fixture index: %s", err)
+	}
+	idx.Entries = append(idx.Entries, &index.Entry{
+		Name: path,
+		Hash: plumbing.NewHash(commit),
+		Mode: filemode.Submodule,
+	})
+	if err := r.Storer.SetIndex(idx); err != nil {
+		t.Fatalf("error writing fixture index: %s", err)
+	}
+
+	return r.Commit(t, "add submodule "+path, nil)
+}
@@ -0,0 +1,530 @@
+package acctest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	backendhttp "github.com/go-git/go-git/v6/backend/http"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v6/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v6/storage"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// loader resolves endpoints to fixture repositories by their path, ignoring
+// host and scheme.
+type loader map[string]*Repository
+
+func (l loader) Load(ep *transport.Endpoint) (storage.Storer, error) {
+	r, ok := l[strings.Trim(ep.Path, "/")]
+	if !ok {
+		return nil, transport.ErrRepositoryNotFound
+	}
+	return r.Storer, nil
+}
+
+// HTTPServer serves fixture repositories over git smart-HTTP, along with
+// their Git LFS objects.
+type HTTPServer struct {
+	*httptest.Server
+
+	// Authorization, if set, is the Authorization header git requests must
+	// carry.
+	Authorization string
+
+	// ClientCertificate and ClientKey are the PEM encoded client certificate
+	// accepted by a server started with NewMTLSServer.
+	ClientCertificate string
+	ClientKey         string
+
+	// LFSAuthorization, if set, is the Authorization header LFS requests
+	// must carry.
+	LFSAuthorization string
+
+	// Fetches counts the git-upload-pack requests served, i.e. the clones and
+	// fetches of any repository. LFSRequests counts the Git LFS requests.
+	Fetches     atomic.Int64
+	LFSRequests atomic.Int64
+
+	repos loader
+	git   http.Handler
+}
+
+// NewHTTPServer starts a plain HTTP git server. repos are keyed by their path
+// on the server, e.g. "fal-ai/demo.git".
+func NewHTTPServer(t testing.TB, repos map[string]*Repository) *HTTPServer {
+	t.Helper()
+
+	s := &HTTPServer{
+		repos: loader(repos),
+		git:   backendhttp.NewBackend(loader(repos)),
+	}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// NewHTTPSServer starts a git server like NewHTTPServer, over TLS. Its
+// certificate is valid for example.com and 127.0.0.1.
+func NewHTTPSServer(t testing.TB, repos map[string]*Repository) *HTTPServer {
+	t.Helper()
+
+	s := &HTTPServer{
+		repos: loader(repos),
+		git:   backendhttp.NewBackend(loader(repos)),
+	}
+	s.Server = httptest.NewTLSServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// NewMTLSServer starts a git server like NewHTTPSServer that only accepts
+// clients presenting ClientCertificate.
+func NewMTLSServer(t testing.TB, repos map[string]*Repository) *HTTPServer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating client key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "acctest-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error creating client certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing client certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("error encoding client key: %s", err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	s := &HTTPServer{
+		ClientCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		ClientKey:         string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		repos:             loader(repos),
+		git:               backendhttp.NewBackend(loader(repos)),
+	}
+	s.Server = httptest.NewUnstartedServer(s)
+	s.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// CertificateAuthority returns the PEM encoded certificate of a server
+// started with NewHTTPSServer or NewMTLSServer.
+func (s *HTTPServer) CertificateAuthority() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}))
+}
+
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	repo, rest, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/info/lfs/")
+	if !ok {
+		if s.Authorization != "" && r.Header.Get("Authorization") != s.Authorization {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/git-upload-pack") {
+			s.Fetches.Add(1)
+		}
+		s.git.ServeHTTP(w, r)
+		return
+	}
+	s.LFSRequests.Add(1)
+
+	fixture, ok := s.repos[repo]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	if s.LFSAuthorization != "" && auth != s.LFSAuthorization {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if oid, ok := strings.CutPrefix(rest, "objects/"); ok && r.Method == http.MethodGet {
+		content, ok := fixture.LFS[oid]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+		return
+	}
+	if rest != "objects/batch" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var batch struct {
+		Objects []struct {
+			Oid  string `json:"oid"`
+			Size int64  `json:"size"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objects := make([]map[string]any, 0, len(batch.Objects))
+	for _, o := range batch.Objects {
+		obj := map[string]any{"oid": o.Oid, "size": o.Size}
+		if _, ok := fixture.LFS[o.Oid]; ok {
+			download := map[string]any{"href": fmt.Sprintf("http://%s/%s/info/lfs/objects/%s", r.Host, repo, o.Oid)}
+			if auth != "" {
+				download["header"] = map[string]string{"Authorization": auth}
+			}
+			obj["actions"] = map[string]any{"download": download}
+		} else {
+			obj["error"] = map[string]any{"code": http.StatusNotFound, "message": "object not found"}
+		}
+		objects = append(objects, obj)
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.git-lfs+json")
+	json.NewEncoder(w).Encode(map[string]any{"transfer": "basic", "objects": objects})
+}
+
+// RepoURL returns the clone URL of the repository served at path.
+func (s *HTTPServer) RepoURL(path string) string {
+	return s.URL + "/" + path
+}
+
+// SSHServer serves fixture repositories over SSH, accepting a single client
+// key.
+type SSHServer struct {
+	Addr    string
+	HostKey ssh.PublicKey
+
+	// ClientKey is the OpenSSH encoded private key accepted by the server.
+	ClientKey string
+
+	// LFS is the server git-lfs-authenticate refers clients to.
+	LFS *HTTPServer
+
+	listener net.Listener
+	config   *ssh.ServerConfig
+	loader   loader
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewSSHServer starts an SSH git server. repos are keyed by their path on the
+// server, e.g. "fal-ai/demo.git".
+func NewSSHServer(t testing.TB, repos map[string]*Repository) *SSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating host key: %s", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("error creating host signer: %s", err)
+	}
+
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating client key: %s", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatalf("error encoding client key: %s", err)
+	}
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatalf("error encoding client public key: %s", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, fmt.Errorf("unknown public key for %q", conn.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %s", err)
+	}
+
+	s := &SSHServer{
+		Addr:      l.Addr().String(),
+		HostKey:   hostSigner.PublicKey(),
+		ClientKey: string(pem.EncodeToMemory(block)),
+		listener:  l,
+		config:    config,
+		loader:    loader(repos),
+		conns:     map[net.Conn]struct{}{},
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+// RepoURL returns the ssh:// clone URL of the repository served at path.
+func (s *SSHServer) RepoURL(path string) string {
+	return "ssh://git@" + s.Addr + "/" + path
+}
+
+// SCPURL returns an scp-like clone URL of the repository served at path,
+// e.g. git@<host>:fal-ai/demo.git. Those cannot carry a port, so host is made
+// an alias of the server like a Host entry in ~/.ssh/config would.
+func (s *SSHServer) SCPURL(t testing.TB, host, path string) string {
+	t.Helper()
+
+	hostname, port, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		t.Fatalf("error parsing server address: %s", err)
+	}
+
+	prev := gitssh.DefaultSSHConfig
+	gitssh.DefaultSSHConfig = sshConfig{host: {"Hostname": hostname, "Port": port}}
+	t.Cleanup(func() { gitssh.DefaultSSHConfig = prev })
+
+	return "git@" + host + ":" + path
+}
+
+// sshConfig stands in for ~/.ssh/config, mapping host aliases to their
+// settings.
+type sshConfig map[string]map[string]string
+
+func (c sshConfig) Get(alias, key string) string {
+	return c[alias][key]
+}
+
+// KnownHosts returns a known_hosts line for the server.
+func (s *SSHServer) KnownHosts() string {
+	return knownhosts.Line([]string{knownhosts.Normalize(s.Addr)}, s.HostKey)
+}
+
+// TrustHostKey writes the server host key to a known_hosts file and points
+// SSH_KNOWN_HOSTS at it, as go-git consults that file by default.
+func (s *SSHServer) TrustHostKey(t *testing.T) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(path, []byte(s.KnownHosts()+"\n"), 0o600); err != nil {
+		t.Fatalf("error writing known_hosts: %s", err)
+	}
+	t.Setenv("SSH_KNOWN_HOSTS", path)
+}
+
+// close stops accepting connections and tears down the ones go-git keeps
+// open between operations.
+func (s *SSHServer) close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *SSHServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *SSHServer) handleConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		s.handleSession(channel, requests)
+	}
+}
+
+func (s *SSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	var gitProtocol string
+	for req := range requests {
+		switch req.Type {
+		case "env":
+			name, value := parseEnvRequest(req.Payload)
+			if name == "GIT_PROTOCOL" {
+				gitProtocol = value
+			}
+			req.Reply(true, nil)
+		case "exec":
+			req.Reply(true, nil)
+			code := s.exec(channel, string(payloadString(req.Payload)), gitProtocol)
+			status := make([]byte, 4)
+			binary.BigEndian.PutUint32(status, uint32(code))
+			channel.SendRequest("exit-status", false, status)
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *SSHServer) exec(channel ssh.Channel, command, gitProtocol string) int {
+	name, arg, _ := strings.Cut(command, " ")
+	if name == "git-lfs-authenticate" {
+		return s.lfsAuthenticate(channel, arg)
+	}
+	if name != transport.UploadPackService.String() {
+		fmt.Fprintf(channel.Stderr(), "unsupported command %q\n", name)
+		return 1
+	}
+
+	ep, err := transport.NewEndpoint(strings.Trim(arg, "'\""))
+	if err != nil {
+		fmt.Fprintln(channel.Stderr(), err)
+		return 1
+	}
+	st, err := s.loader.Load(ep)
+	if err != nil {
+		fmt.Fprintln(channel.Stderr(), err)
+		return 1
+	}
+
+	err = transport.UploadPack(context.Background(), st, io.NopCloser(channel), nopWriteCloser{channel}, &transport.UploadPackOptions{
+		GitProtocol: gitProtocol,
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		fmt.Fprintln(channel.Stderr(), err)
+		return 1
+	}
+
+	// the client closes its side once it has read the server response, wait
+	// for that before tearing down the channel
+	io.Copy(io.Discard, channel)
+	return 0
+}
+
+func (s *SSHServer) lfsAuthenticate(channel ssh.Channel, arg string) int {
+	path, operation, _ := strings.Cut(arg, " ")
+	path = strings.Trim(path, "'\"")
+	if s.LFS == nil || operation != "download" {
+		fmt.Fprintf(channel.Stderr(), "git-lfs-authenticate: unsupported %q\n", arg)
+		return 1
+	}
+
+	response := map[string]any{"href": s.LFS.RepoURL(path) + "/info/lfs"}
+	if s.LFS.LFSAuthorization != "" {
+		response["header"] = map[string]string{"Authorization": s.LFS.LFSAuthorization}
+	}
+	json.NewEncoder(channel).Encode(response)
+	return 0
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// payloadString decodes an SSH wire string from the start of payload.
+func payloadString(payload []byte) []byte {
+	if len(payload) < 4 {
+		return nil
+	}
+	n := binary.BigEndian.Uint32(payload)
+	if uint32(len(payload)-4) < n {
+		return nil
+	}
+	return payload[4 : 4+n]
+}
+
+func parseEnvRequest(payload []byte) (string, string) {
+	name := payloadString(payload)
+	if name == nil {
+		return "", ""
+	}
+	return string(name), string(payloadString(payload[4+len(name):]))
+}
@@ -0,0 +1,133 @@
+package acctest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v6"
+	"golang.org/x/crypto/ssh"
+)
+
+// GPGSigner signs fixture commits and tags with a fresh GPG key.
+type GPGSigner struct {
+	entity *openpgp.Entity
+
+	// PublicKey is the armored public key of the signer.
+	PublicKey string
+}
+
+var _ git.Signer = (*GPGSigner)(nil)
+
+// NewGPGSigner generates a GPG key to sign fixtures with.
+func NewGPGSigner(t testing.TB) *GPGSigner {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("fal acceptance", "", "acceptance@fal.ai", &packet.Config{
+		Algorithm: packet.PubKeyAlgoEdDSA,
+	})
+	if err != nil {
+		t.Fatalf("error generating gpg key: %s", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("error armoring gpg key: %s", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("error serialising gpg key: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error armoring gpg key: %s", err)
+	}
+
+	return &GPGSigner{entity: entity, PublicKey: buf.String()}
+}
+
+func (s *GPGSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, message, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SSHSigner signs fixture commits and tags with a fresh SSH key, like git
+// does with gpg.format set to ssh.
+type SSHSigner struct {
+	signer ssh.Signer
+
+	// AllowedSigners is an allowed_signers line trusting the signer.
+	AllowedSigners string
+}
+
+var _ git.Signer = (*SSHSigner)(nil)
+
+// NewSSHSigner generates an SSH key to sign fixtures with.
+func NewSSHSigner(t testing.TB) *SSHSigner {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating ssh signing key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("error creating ssh signer: %s", err)
+	}
+
+	return &SSHSigner{
+		signer:         signer,
+		AllowedSigners: "acceptance@fal.ai " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+	}
+}
+
+// Sign returns an armored signature of message in the git namespace, see
+// PROTOCOL.sshsig of OpenSSH.
+func (s *SSHSigner) Sign(message io.Reader) ([]byte, error) {
+	const magic = "SSHSIG"
+
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, err
+	}
+
+	signed := append([]byte(magic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{"git", "", "sha512", h.Sum(nil)})...)
+	sig, err := s.signer.Sign(rand.Reader, signed)
+	if err != nil {
+		return nil, err
+	}
+
+	blob := append([]byte(magic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, s.signer.PublicKey().Marshal(), "git", "", "sha512", ssh.Marshal(sig)})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var buf bytes.Buffer
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		buf.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	buf.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,168 @@
+package acctest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// App is an app as tracked by the fake fal CLI.
+type App struct {
+	Alias      string `json:"alias"`
+	Revision   string `json:"revision"`
+	AuthMode   string `json:"auth_mode"`
+	Strategy   string `json:"strategy"`
+	Entrypoint string `json:"entrypoint"`
+
+	CreatedAt   string `json:"created_at"`
+	MachineType string `json:"machine_type"`
+	GitCommit   string `json:"git_commit"`
+}
+
+// Call is a single invocation of one of the stub executables.
+type Call struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+
+	// Env holds the RecordedEnv variables the stub was run with.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// RecordedEnv lists the environment variables recorded for every call.
+var RecordedEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY", "SSL_CERT_FILE", "GIT_ASKPASS", "GIT_SSH_COMMAND", "UV_DEFAULT_INDEX", "UV_INDEX", "UV_INDEX_FAL_DEFAULT_USERNAME", "PIP_INDEX_URL"}
+
+// State is the persisted state shared by the stub executables.
+type State struct {
+	Apps      []*App `json:"apps"`
+	Revisions int    `json:"revisions"`
+	Calls     []Call `json:"calls"`
+
+	// History holds every revision ever deployed, oldest first.
+	History []*App `json:"history"`
+
+	// Failures maps fal commands, e.g. "apps delete", to an error the fal
+	// stub exits with instead of running them.
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// CallsTo returns the recorded invocations of the stub name whose arguments
+// start with args.
+func (s *State) CallsTo(name string, args ...string) []Call {
+	var calls []Call
+	for _, c := range s.Calls {
+		if c.Name == name && len(c.Args) >= len(args) && slices.Equal(c.Args[:len(args)], args) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Revision returns the deployed revision with the given id, or nil.
+func (s *State) Revision(id string) *App {
+	for _, r := range s.History {
+		if r.Revision == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// App returns the app registered under alias, or nil.
+func (s *State) App(alias string) *App {
+	for _, a := range s.Apps {
+		if a.Alias == alias {
+			return a
+		}
+	}
+	return nil
+}
+
+// Remove drops the app registered under alias and reports whether it existed.
+func (s *State) Remove(alias string) bool {
+	for i, a := range s.Apps {
+		if a.Alias == alias {
+			s.Apps = append(s.Apps[:i], s.Apps[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	lockRetries  = 500
+	lockInterval = 10 * time.Millisecond
+
+	// staleLockAge is how long a lock may be held before it is considered
+	// abandoned. The provider kills stubs it stops waiting for when a request
+	// context ends, which can happen while one of them holds the lock.
+	staleLockAge = 2 * time.Second
+)
+
+// updateState loads the state file at path, applies fn and writes it back.
+// A sibling lock file serialises concurrent stub invocations.
+func updateState(path string, fn func(*State) error) error {
+	unlock, err := lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := readState(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// readers do not take the lock, so replace the file atomically
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readState(path string) (*State, error) {
+	var state State
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("error decoding state file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func lock(path string) (func(), error) {
+	for i := 0; i < lockRetries; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(lockInterval)
+	}
+	return nil, fmt.Errorf("timed out waiting for lock %s", path)
+}
@@ -16,6 +16,17 @@ type (
 )
 
 func Exec(ctx context.Context, name string, o ...Opt) (<-chan []byte, error) {
+	executor := &readableExecutor{}
+	return executor.Exec(newCommand(ctx, name, o...))
+}
+
+// Run runs name until it exits and returns its combined stdout and stderr.
+// Unlike Exec, it reports a non-zero exit status as an error.
+func Run(ctx context.Context, name string, o ...Opt) ([]byte, error) {
+	return newCommand(ctx, name, o...).CombinedOutput()
+}
+
+func newCommand(ctx context.Context, name string, o ...Opt) *exec.Cmd {
 	var options opts
 	for _, opt := range o {
 		opt(&options)
@@ -31,9 +42,7 @@ func Exec(ctx context.Context, name string, o ...Opt) (<-chan []byte, error) {
 		}
 		cmd.Env = append(cmd.Environ(), env...)
 	}
-
-	executor := &readableExecutor{}
-	return executor.Exec(cmd)
+	return cmd
 }
 
 func WithArgs(arg string, args ...string) Opt {
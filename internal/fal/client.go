@@ -2,7 +2,15 @@ package fal
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
+	"github.com/fal-ai/terraform-provider-fal/internal/runner"
 )
 
 type DeployStrategy string
@@ -21,31 +29,116 @@ const (
 )
 
 type Client struct {
-	key string
-	dir string
+	key   string
+	dir   string
+	proxy *git.Proxy
+
+	// proxyEnv routes every runner and fal command through proxy
+	proxyEnv map[string]string
+	// pythonIndex is where the runner installs packages from unless an app
+	// has its own
+	pythonIndex *runner.PythonIndex
+	// runner manages the Python environments fal runs in
+	runner runner.Kind
+
+	mu         sync.Mutex
+	workspaces map[string]*workspace
 }
 
-func NewWithTemp(key string) (*Client, error) {
+// NewWithTemp returns a client working from a new temporary directory that
+// runs fal in Python environments managed by the runner of kind. Git sources,
+// the runner and fal go through proxy if it is not nil, and the runner
+// installs packages from pythonIndex if it is not nil.
+func NewWithTemp(key string, proxy *git.Proxy, pythonIndex *runner.PythonIndex, kind runner.Kind) (*Client, error) {
 	dir, err := os.MkdirTemp("", "fal-*")
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		key: key,
-		dir: dir,
-	}, nil
+	f := &Client{
+		key:      key,
+		dir:      dir,
+		proxy:    proxy,
+		proxyEnv: map[string]string{},
+
+		pythonIndex: pythonIndex,
+		runner:      kind,
+	}
+
+	if proxy != nil && proxy.URL != "" {
+		f.proxyEnv["HTTP_PROXY"] = proxy.URL
+		f.proxyEnv["HTTPS_PROXY"] = proxy.URL
+		f.proxyEnv["ALL_PROXY"] = proxy.URL
+		f.proxyEnv["NO_PROXY"] = proxy.NoProxy
+	}
+	if proxy != nil && len(proxy.CABundle) > 0 {
+		caFile := filepath.Join(dir, "proxy-ca.pem")
+		if err := os.WriteFile(caFile, proxy.CABundle, 0o600); err != nil {
+			return nil, fmt.Errorf("error writing proxy certificate authority: %w", err)
+		}
+		f.proxyEnv["SSL_CERT_FILE"] = caFile
+		// pip and Poetry ignore SSL_CERT_FILE
+		f.proxyEnv["PIP_CERT"] = caFile
+		f.proxyEnv["REQUESTS_CA_BUNDLE"] = caFile
+	}
+	return f, nil
 }
 
 func (f *Client) Directory() string {
 	return f.dir
 }
 
+// Proxy returns the proxy git sources are fetched through, nil if there is
+// none.
+func (f *Client) Proxy() *git.Proxy {
+	return f.proxy
+}
+
 func (f *Client) sharedEnvironmentVariables() map[string]string {
 	return map[string]string{
 		"FAL_KEY": f.key,
 	}
 }
 
+// runFal runs a fal CLI command from the client's scratch environment,
+// installing fal into it first.
+func (f *Client) runFal(ctx context.Context, args ...string) (<-chan []byte, error) {
+	r, err := f.falRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Run(ctx, f.sharedEnvironmentVariables(), append([]string{"fal"}, args...)...)
+}
+
+// outputFal runs a fal CLI command like runFal and returns its output once it
+// exits, failing if fal does.
+func (f *Client) outputFal(ctx context.Context, args ...string) ([]byte, error) {
+	r, err := f.falRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Output(ctx, f.sharedEnvironmentVariables(), append([]string{"fal"}, args...)...)
+}
+
+// falRunner returns the runner of the client's scratch environment with fal
+// installed into it.
+func (f *Client) falRunner(ctx context.Context) (runner.Runner, error) {
+	r, err := runner.New(f.runner, f.dir, runner.Options{
+		Environment: f.proxyEnv,
+		PythonIndex: f.pythonIndex,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := r.Bootstrap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error installing fal client into new env: %w: %s", err, readAll(c))
+	}
+	// fal is run once it is installed
+	readAll(c)
+	return r, nil
+}
+
 func readAll(c <-chan []byte) string {
 	var output bytes.Buffer
 
@@ -62,3 +155,11 @@ wait:
 
 	return output.String()
 }
+
+func readLines(c <-chan []byte) []string {
+	var lines []string
+	for b := range c {
+		lines = append(lines, strings.TrimRight(string(b), "\r\n"))
+	}
+	return lines
+}
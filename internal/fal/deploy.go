@@ -2,100 +2,196 @@ package fal
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"regexp"
-	"strings"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/fal-ai/terraform-provider-fal/internal/git"
 	"github.com/fal-ai/terraform-provider-fal/internal/runner"
 )
 
 type DeployOpts struct {
+	// Branch is the branch to deploy from, the remote's HEAD if empty.
+	Branch string
+	// Path is the project root relative to the repository root. uv and fal
+	// run from it and Entrypoint is relative to it.
+	Path string
+	// Depth is the number of commits of history to clone, all of them if
+	// zero.
+	Depth int
+	// SparsePaths limits the checkout to these directories and the files at
+	// the repository root if not empty.
+	SparsePaths []string
+	// Submodules and LFS fetch submodules and Git LFS objects with the clone.
+	Submodules bool
+	LFS        bool
+	// Verify requires the deployed commit to be signed by a trusted key.
+	Verify *git.SignatureOpts
+	// PythonIndex overrides the client's package indexes if not nil.
+	PythonIndex *runner.PythonIndex
+	// LockfileMode is how uv treats the project's uv.lock.
+	LockfileMode runner.LockfileMode
+
+	// AppName overrides the name fal derives from the entrypoint.
+	AppName    string
 	Entrypoint string
 	Strategy   DeployStrategy
 	AuthMode   AuthMode
 }
 
-func (f *Client) Deploy(ctx context.Context, git *git.Client, repo string, opts *DeployOpts) (*DeployResult, error) {
-	u := parseGitURL(repo)
-	path := f.dir + "/" + u.Repo
-
-	if err := git.Clone(ctx, path, repo); err != nil {
-		return nil, fmt.Errorf("error cloning git repo: %w", err)
+func (f *Client) Deploy(ctx context.Context, gitClient *git.Client, repo string, opts *DeployOpts) (*DeployResult, error) {
+	// uv fetches git dependencies with git, which gets the repository's
+	// credentials for the duration of the deployment
+	credentials, err := os.MkdirTemp(f.dir, "git-credentials-*")
+	if err != nil {
+		return nil, err
 	}
+	defer os.RemoveAll(credentials)
 
-	uv := runner.FromUv(path)
-
-	if c, err := uv.Sync(ctx); err != nil {
-		return nil, fmt.Errorf("error syncing virtual environment: %w: %s", err, readAll(c))
+	env, err := gitClient.CredentialEnv(credentials, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error forwarding git credentials: %w", err)
 	}
+	maps.Copy(env, f.proxyEnv)
 
-	strategyFlag := fmt.Sprintf("--strategy=%s", opts.Strategy)
-	authModeFlag := fmt.Sprintf("--auth=%s", opts.AuthMode)
-	env := f.sharedEnvironmentVariables()
+	path, lockfileHash, err := f.checkout(ctx, gitClient, repo, env, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	c, err := uv.Run(ctx, env, "fal", "deploy", strategyFlag, authModeFlag, opts.Entrypoint)
+	r, err := f.deploy(ctx, path, env, opts, true)
+	if errors.Is(err, errJSONOutputUnsupported) {
+		// older fal versions reject --output before deploying anything, so it
+		// is safe to deploy again and scrape the human readable output
+		r, err = f.deploy(ctx, path, env, opts, false)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error running fal deploy: %w: %s", err, readAll(c))
+		return nil, err
 	}
 
-	r := parseDeployResult(c)
-	if r.FunctionName == "" && r.Revision == "" {
+	if r.FunctionName == "" || r.Revision == "" {
 		return nil, fmt.Errorf("deployment failed: %s", r.Output)
 	}
+	if opts.AppName != "" && r.FunctionName != opts.AppName {
+		return nil, fmt.Errorf("app was deployed as %s instead of %s: %s", r.FunctionName, opts.AppName, r.Output)
+	}
 
+	r.LockfileHash = lockfileHash
 	return r, nil
 }
 
-var (
-	functionRe = regexp.MustCompile(`function '([^']+)'`)
-	revisionRe = regexp.MustCompile(`revision='([^']+)'`)
+// LockfileHash returns the hash of the lockfile of the project Deploy would
+// deploy with opts, or an empty string if the project has none. Only the
+// lockfile is read from the commit the branch points at, nothing is checked
+// out.
+func (f *Client) LockfileHash(ctx context.Context, gitClient *git.Client, repo string, opts *DeployOpts) (string, error) {
+	name := f.runner.Lockfile()
+	if name == "" {
+		return "", nil
+	}
 
-	completedResultLine = "Registered a new revision for function"
-)
+	commit, err := gitClient.Resolve(ctx, repo, opts.Branch)
+	if err != nil {
+		return "", fmt.Errorf("error resolving git reference: %w", err)
+	}
+	project := opts.Path
+	if project == "" {
+		project = "."
+	}
+	content, err := gitClient.ReadFile(ctx, f.dir, repo, commit, path.Join(filepath.ToSlash(project), name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return hashContent(content), nil
+}
 
-type DeployResult struct {
-	FunctionName string
-	Revision     string
+// hashLockfile returns the hash of the lockfile of the runner in the project
+// at path, or an empty string if there is none.
+func (f *Client) hashLockfile(path string) (string, error) {
+	name := f.runner.Lockfile()
+	if name == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(filepath.Join(path, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return hashContent(content), nil
+}
 
-	Output string
+// hashContent returns the hex encoded SHA-256 of a lockfile.
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
 }
 
-func parseDeployResult(bytes <-chan []byte) *DeployResult {
-	output := strings.Builder{}
-	for b := range bytes {
-		line := string(b)
-		output.WriteString("\n")
-		output.WriteString(line)
+// pythonIndexFor returns the package indexes the project of opts is
+// installed from.
+func (f *Client) pythonIndexFor(opts *DeployOpts) *runner.PythonIndex {
+	if opts.PythonIndex != nil {
+		return opts.PythonIndex
+	}
+	return f.pythonIndex
+}
 
-		if !strings.Contains(line, completedResultLine) {
-			continue
-		}
+// ValidateRunner returns an error if the client's runner cannot deploy with
+// the package indexes and lockfile mode of opts.
+func (f *Client) ValidateRunner(opts *DeployOpts) error {
+	return runner.Validate(f.runner, runner.Options{
+		PythonIndex: f.pythonIndexFor(opts),
+		Lockfile:    opts.LockfileMode,
+	})
+}
 
-		matches := functionRe.FindStringSubmatch(line)
-		if len(matches) != 2 {
-			continue
-		}
+// projectRunner returns the runner for the project at path deployed with
+// opts. env is set for every command.
+func (f *Client) projectRunner(path string, env map[string]string, opts *DeployOpts) (runner.Runner, error) {
+	return runner.New(f.runner, path, runner.Options{
+		Environment: env,
+		PythonIndex: f.pythonIndexFor(opts),
+		Lockfile:    opts.LockfileMode,
+	})
+}
 
-		nextBytes, ok := <-bytes
-		if !ok {
-			return nil
-		}
+func (f *Client) deploy(ctx context.Context, path string, env map[string]string, opts *DeployOpts, jsonOutput bool) (*DeployResult, error) {
+	r, err := f.projectRunner(path, env, opts)
+	if err != nil {
+		return nil, err
+	}
 
-		nextLine := string(nextBytes)
-		revMatches := revisionRe.FindStringSubmatch(nextLine)
-		if len(revMatches) != 2 {
-			continue
-		}
+	args := []string{
+		"fal", "deploy",
+		fmt.Sprintf("--strategy=%s", opts.Strategy),
+		fmt.Sprintf("--auth=%s", opts.AuthMode),
+	}
+	if opts.AppName != "" {
+		args = append(args, fmt.Sprintf("--app-name=%s", opts.AppName))
+	}
+	if jsonOutput {
+		args = append(args, "--output", "json")
+	}
+	args = append(args, opts.Entrypoint)
 
-		return &DeployResult{
-			FunctionName: matches[1],
-			Revision:     revMatches[1],
-			Output:       output.String(),
-		}
+	c, err := r.Run(ctx, f.sharedEnvironmentVariables(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error running fal deploy: %w: %s", err, readAll(c))
 	}
 
-	return &DeployResult{
-		Output: output.String(),
+	lines := readLines(c)
+	if !jsonOutput {
+		return parseLegacyDeployResult(lines), nil
 	}
+	return parseDeployResult(lines)
 }
@@ -0,0 +1,134 @@
+package fal
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	functionRe = regexp.MustCompile(`function '([^']+)'`)
+	revisionRe = regexp.MustCompile(`revision='([^']+)'`)
+	urlRe      = regexp.MustCompile(`(?:https?|wss?)://\S+`)
+
+	completedResultLine = "Registered a new revision for function"
+	endpointsHeading    = "Endpoints"
+	warningPrefix       = "warning:"
+
+	// fal versions without --output support reject it through argparse/click
+	// before anything is deployed.
+	unsupportedOutputMarkers = []string{
+		"unrecognized arguments: --output",
+		"No such option: --output",
+	}
+
+	errJSONOutputUnsupported = errors.New("fal deploy does not support --output json")
+)
+
+type DeployResult struct {
+	FunctionName string
+	Revision     string
+	Endpoints    []string
+	Warnings     []string
+	// LockfileHash is the hash of the deployed project's uv.lock, empty if it
+	// has none.
+	LockfileHash string
+
+	Output string
+}
+
+// deployOutput is the document printed by `fal deploy --output json`.
+type deployOutput struct {
+	AppName   string   `json:"app_name"`
+	Revision  string   `json:"revision"`
+	Endpoints []string `json:"endpoints"`
+	Warnings  []string `json:"warnings"`
+}
+
+// parseDeployResult parses the output of `fal deploy --output json`. Log lines
+// around the JSON document are ignored. When no document is found, the output
+// is handed to the legacy parser instead.
+func parseDeployResult(lines []string) (*DeployResult, error) {
+	output := strings.Join(lines, "\n")
+
+	if rejected(output, unsupportedOutputMarkers) {
+		return nil, errJSONOutputUnsupported
+	}
+
+	var doc deployOutput
+	if err := decodeJSONDocument(lines, &doc); err == nil && doc.Revision != "" {
+		return &DeployResult{
+			FunctionName: doc.AppName,
+			Revision:     doc.Revision,
+			Endpoints:    doc.Endpoints,
+			Warnings:     doc.Warnings,
+			Output:       output,
+		}, nil
+	}
+
+	return parseLegacyDeployResult(lines), nil
+}
+
+// rejected reports whether output shows fal rejecting an argument through
+// one of markers.
+func rejected(output string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLegacyDeployResult scrapes the human readable output of `fal deploy`.
+// Depending on the fal version and the terminal width, the revision is printed
+// on the same line as the function name or wrapped onto one of the following
+// lines, and endpoint URLs may be listed under one or more "Endpoints"
+// headings.
+func parseLegacyDeployResult(lines []string) *DeployResult {
+	r := &DeployResult{
+		Output: strings.Join(lines, "\n"),
+	}
+
+	inEndpoints := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(strings.ToLower(trimmed), warningPrefix) {
+			r.Warnings = append(r.Warnings, strings.TrimSpace(trimmed[len(warningPrefix):]))
+			continue
+		}
+
+		if r.FunctionName == "" && strings.Contains(line, completedResultLine) {
+			if matches := functionRe.FindStringSubmatch(line); len(matches) == 2 {
+				r.FunctionName = matches[1]
+			}
+		}
+
+		if r.FunctionName != "" && r.Revision == "" {
+			if matches := revisionRe.FindStringSubmatch(line); len(matches) == 2 {
+				r.Revision = matches[1]
+			}
+		}
+
+		if strings.HasSuffix(trimmed, ":") {
+			inEndpoints = strings.Contains(trimmed, endpointsHeading)
+			continue
+		}
+
+		if inEndpoints {
+			if u := urlRe.FindString(trimmed); u != "" && u == trimmed {
+				r.Endpoints = append(r.Endpoints, u)
+				continue
+			}
+			inEndpoints = false
+		}
+	}
+
+	// a revision without its function is not a completed deployment
+	if r.FunctionName == "" {
+		r.Revision = ""
+	}
+
+	return r
+}
@@ -0,0 +1,147 @@
+package fal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", "deploy", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+}
+
+func TestParseDeployResult(t *testing.T) {
+	const revision = "9f2b3c1e-7a4d-4c55-9e0b-3f6a1d2e8b90"
+
+	tests := []struct {
+		fixture string
+		json    bool
+		want    *DeployResult
+		wantErr error
+	}{
+		{
+			fixture: "legacy_single_line.txt",
+			want:    &DeployResult{FunctionName: "sana", Revision: revision},
+		},
+		{
+			fixture: "legacy_wrapped.txt",
+			want: &DeployResult{
+				FunctionName: "sana",
+				Revision:     revision,
+				Endpoints: []string{
+					"https://fal.run/fal-ai-community/sana",
+					"https://queue.fal.run/fal-ai-community/sana",
+				},
+			},
+		},
+		{
+			fixture: "legacy_endpoints.txt",
+			want: &DeployResult{
+				FunctionName: "sana",
+				Revision:     revision,
+				Endpoints: []string{
+					"https://fal.run/fal-ai-community/sana",
+					"https://queue.fal.run/fal-ai-community/sana",
+					"wss://ws.fal.run/fal-ai-community/sana",
+				},
+				Warnings: []string{"the 'keep_alive' setting is deprecated, use 'min_concurrency' instead"},
+			},
+		},
+		{
+			fixture: "legacy_truncated.txt",
+			want:    &DeployResult{FunctionName: "sana"},
+		},
+		{
+			fixture: "legacy_failed.txt",
+			want:    &DeployResult{},
+		},
+		{
+			fixture: "json.txt",
+			json:    true,
+			want: &DeployResult{
+				FunctionName: "sana",
+				Revision:     revision,
+				Endpoints: []string{
+					"https://fal.run/fal-ai-community/sana",
+					"https://queue.fal.run/fal-ai-community/sana",
+					"wss://ws.fal.run/fal-ai-community/sana",
+				},
+				Warnings: []string{"the 'keep_alive' setting is deprecated, use 'min_concurrency' instead"},
+			},
+		},
+		{
+			fixture: "json_pretty.txt",
+			json:    true,
+			want: &DeployResult{
+				FunctionName: "sana",
+				Revision:     revision,
+				Endpoints:    []string{"https://fal.run/fal-ai-community/sana"},
+			},
+		},
+		{
+			// versions without JSON support still print the legacy output
+			fixture: "legacy_wrapped.txt",
+			json:    true,
+			want: &DeployResult{
+				FunctionName: "sana",
+				Revision:     revision,
+				Endpoints: []string{
+					"https://fal.run/fal-ai-community/sana",
+					"https://queue.fal.run/fal-ai-community/sana",
+				},
+			},
+		},
+		{
+			fixture: "json_unsupported_argparse.txt",
+			json:    true,
+			wantErr: errJSONOutputUnsupported,
+		},
+		{
+			fixture: "json_unsupported_click.txt",
+			json:    true,
+			wantErr: errJSONOutputUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		name := tt.fixture
+		if tt.json {
+			name = "json/" + name
+		}
+		t.Run(name, func(t *testing.T) {
+			lines := readFixture(t, tt.fixture)
+
+			var got *DeployResult
+			var err error
+			if tt.json {
+				got, err = parseDeployResult(lines)
+			} else {
+				got = parseLegacyDeployResult(lines)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Output != strings.Join(lines, "\n") {
+				t.Errorf("expected the full output to be kept, got %q", got.Output)
+			}
+			got.Output = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package fal
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	syncHost      = "fal.run"
+	queueHost     = "queue.fal.run"
+	websocketHost = "ws.fal.run"
+)
+
+// Endpoints are the public URLs an app is served on.
+type Endpoints struct {
+	Sync      string
+	Queue     string
+	WebSocket string
+}
+
+// EndpointsFor builds the URLs fal serves app under for owner.
+func EndpointsFor(owner, app string) *Endpoints {
+	return &Endpoints{
+		Sync:      "https://" + syncHost + "/" + owner + "/" + app,
+		Queue:     "https://" + queueHost + "/" + owner + "/" + app,
+		WebSocket: "wss://" + websocketHost + "/" + owner + "/" + app,
+	}
+}
+
+// OwnerFromEndpoint returns the owner segment of an app URL, or an empty
+// string if endpoint is not one.
+func OwnerFromEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	owner, _, found := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if !found {
+		return ""
+	}
+	return owner
+}
+
+// AppEndpoints returns the URLs of the deployed app. URLs reported by fal are
+// used as is and the rest is derived from the owner they were reported under.
+// It returns nil when fal did not report any URL.
+func (r *DeployResult) AppEndpoints() *Endpoints {
+	var owner string
+	var reported Endpoints
+	for _, endpoint := range r.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+		switch u.Host {
+		case syncHost:
+			reported.Sync = endpoint
+		case queueHost:
+			reported.Queue = endpoint
+		case websocketHost:
+			reported.WebSocket = endpoint
+		default:
+			continue
+		}
+		if owner == "" {
+			owner = OwnerFromEndpoint(endpoint)
+		}
+	}
+
+	if owner == "" {
+		return nil
+	}
+
+	e := EndpointsFor(owner, r.FunctionName)
+	if reported.Sync != "" {
+		e.Sync = reported.Sync
+	}
+	if reported.Queue != "" {
+		e.Queue = reported.Queue
+	}
+	if reported.WebSocket != "" {
+		e.WebSocket = reported.WebSocket
+	}
+	return e
+}
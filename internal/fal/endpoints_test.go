@@ -0,0 +1,49 @@
+package fal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeployResultAppEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      *Endpoints
+	}{
+		{
+			name: "all reported",
+			endpoints: []string{
+				"https://fal.run/fal-ai-community/sana",
+				"https://queue.fal.run/fal-ai-community/sana",
+				"wss://ws.fal.run/fal-ai-community/sana",
+			},
+			want: &Endpoints{
+				Sync:      "https://fal.run/fal-ai-community/sana",
+				Queue:     "https://queue.fal.run/fal-ai-community/sana",
+				WebSocket: "wss://ws.fal.run/fal-ai-community/sana",
+			},
+		},
+		{
+			name:      "derived from owner",
+			endpoints: []string{"https://queue.fal.run/fal-ai-community/sana"},
+			want:      EndpointsFor("fal-ai-community", "sana"),
+		},
+		{
+			name:      "playground only",
+			endpoints: []string{"https://fal.ai/models/fal-ai-community/sana"},
+		},
+		{
+			name: "none reported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &DeployResult{FunctionName: "sana", Endpoints: tt.endpoints}
+			if got := r.AppEndpoints(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package fal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxHealthCheckBody = 4 * 1024
+)
+
+type HealthCheckOpts struct {
+	URL            string
+	Payload        string
+	ExpectedStatus int
+	Timeout        time.Duration
+	Retries        int
+	Interval       time.Duration
+	// Headers are set on every request, after the fal API key.
+	Headers map[string]string
+}
+
+// HealthCheckError is returned when an app never passed its health check. It
+// carries the last response so it can be shown to the user.
+type HealthCheckError struct {
+	Attempts int
+	Status   int
+	Body     string
+	Err      error
+}
+
+func (e *HealthCheckError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("app did not become healthy after %d attempts: %s", e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("app did not become healthy after %d attempts: got status %d: %s", e.Attempts, e.Status, e.Body)
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// WaitHealthy polls the app until it answers with the expected status. The
+// request is a GET, or a POST when a payload is set.
+func (f *Client) WaitHealthy(ctx context.Context, opts *HealthCheckOpts) error {
+	client := &http.Client{Timeout: opts.Timeout}
+
+	var last *HealthCheckError
+	for attempt := 1; attempt <= opts.Retries+1; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Interval):
+			}
+		}
+
+		status, body, err := f.healthCheck(ctx, client, opts)
+		if err == nil && status == opts.ExpectedStatus {
+			return nil
+		}
+		last = &HealthCheckError{Attempts: attempt, Status: status, Body: body, Err: err}
+	}
+	return last
+}
+
+func (f *Client) healthCheck(ctx context.Context, client *http.Client, opts *HealthCheckOpts) (int, string, error) {
+	method := http.MethodGet
+	var payload io.Reader
+	if opts.Payload != "" {
+		method = http.MethodPost
+		payload = strings.NewReader(opts.Payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, opts.URL, payload)
+	if err != nil {
+		return 0, "", err
+	}
+	// the API key must not leak to health endpoints outside of fal
+	if isFalHost(req.URL) {
+		req.Header.Set("Authorization", "Key "+f.key)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthCheckBody))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// isFalHost reports whether u points at one of the hosts fal serves apps on,
+// e.g. fal.run or queue.fal.run.
+func isFalHost(u *url.URL) bool {
+	host := u.Hostname()
+	return u.Scheme == "https" && (host == "fal.run" || strings.HasSuffix(host, ".fal.run"))
+}
@@ -0,0 +1,33 @@
+package fal
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestIsFalHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://fal.run/fal-ai-community/sana/health", want: true},
+		{url: "https://queue.fal.run/fal-ai-community/sana", want: true},
+		{url: "https://fal.run:443/fal-ai-community/sana", want: true},
+		{url: "http://fal.run/fal-ai-community/sana"},
+		{url: "https://fal.run.example.com/health"},
+		{url: "https://notfal.run/health"},
+		{url: "https://health.example.com/fal.run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isFalHost(u); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,14 +2,13 @@ package fal
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-
-	"github.com/fal-ai/terraform-provider-fal/internal/runner"
+	"strings"
 )
 
 type App struct {
 	Alias             string   `json:"alias"`
+	Owner             string   `json:"owner"`
 	Revision          string   `json:"revision"`
 	AuthMode          string   `json:"auth_mode"`
 	KeepAlive         int      `json:"keep_alive"`
@@ -25,30 +24,18 @@ type App struct {
 }
 
 func (f *Client) List(ctx context.Context) ([]*App, error) {
-	uv := runner.FromUv(f.dir)
-
-	if c, err := uv.Init(ctx); err != nil {
-		return nil, fmt.Errorf("error calling uv init: %w: %s", err, readAll(c))
-	}
-
-	if c, err := uv.Add(ctx, "fal"); err != nil {
-		return nil, fmt.Errorf("error adding fal client into new env: %w: %s", err, readAll(c))
-	}
-
-	env := f.sharedEnvironmentVariables()
-
-	c, err := uv.Run(ctx, env, "fal", "apps", "list", "--output", "json")
+	c, err := f.runFal(ctx, "apps", "list", "--output", "json")
 	if err != nil {
 		return nil, fmt.Errorf("error running fal apps list in uv environment: %w: %s", err, readAll(c))
 	}
 
-	output := readAll(c)
+	lines := readLines(c)
 
 	var result struct {
 		Apps []*App `json:"apps"`
 	}
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return nil, err
+	if err := decodeJSONDocument(lines, &result); err != nil {
+		return nil, fmt.Errorf("error decoding apps: %w: %s", err, strings.Join(lines, "\n"))
 	}
 	return result.Apps, nil
 }
@@ -0,0 +1,30 @@
+package fal
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var errNoJSONDocument = errors.New("no JSON document in output")
+
+// decodeJSONDocument decodes the first JSON object in the output lines of a
+// fal command run with --output json into v. fal writes logs and warnings to
+// stderr, which is read together with the document, so lines around it are
+// ignored.
+func decodeJSONDocument(lines []string, v any) error {
+	err := errNoJSONDocument
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+			continue
+		}
+
+		// the document may be pretty printed, so decode from this line on and
+		// ignore whatever follows it
+		dec := json.NewDecoder(strings.NewReader(strings.Join(lines[i:], "\n")))
+		if err = dec.Decode(v); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package fal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeJSONDocument(t *testing.T) {
+	type revisions struct {
+		Revisions []*Revision `json:"revisions"`
+	}
+
+	tests := []struct {
+		name    string
+		output  []string
+		want    revisions
+		wantErr error
+	}{
+		{
+			name:   "document only",
+			output: []string{`{"revisions": [{"revision": "rev-1"}]}`},
+			want:   revisions{Revisions: []*Revision{{Revision: "rev-1"}}},
+		},
+		{
+			name: "warnings around the document",
+			output: []string{
+				"Warning: fal 1.2 is deprecated, please upgrade",
+				`{"revisions": [{"revision": "rev-1"}]}`,
+				"DeprecationWarning: --output is deprecated",
+			},
+			want: revisions{Revisions: []*Revision{{Revision: "rev-1"}}},
+		},
+		{
+			name:   "pretty printed",
+			output: []string{"warning: slow network", "{", `  "revisions": [`, `    {"revision": "rev-1"}`, "  ]", "}"},
+			want:   revisions{Revisions: []*Revision{{Revision: "rev-1"}}},
+		},
+		{
+			name:    "no document",
+			output:  []string{"Error: not authenticated"},
+			wantErr: errNoJSONDocument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got revisions
+			err := decodeJSONDocument(tt.output, &got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package fal
 
 import (
 	"strings"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
 )
 
 type GitURL struct {
@@ -10,31 +12,23 @@ type GitURL struct {
 	Repo  string
 }
 
+// parseGitURL splits http(s), ssh and scp-like (git@github.com:owner/repo)
+// URLs into their host, owner and repository.
 func parseGitURL(gitURL string) *GitURL {
-	clean := strings.TrimSuffix(gitURL, ".git")
-
-	var parts []string
-
-	// SSH: git@github.com:owner/repo
-	if strings.Contains(clean, "@") && strings.Contains(clean, ":") {
-		clean = strings.TrimPrefix(clean, "git@")
-		parts = strings.FieldsFunc(clean, func(r rune) bool {
-			return r == ':' || r == '/'
-		})
-	} else {
-		// HTTP: https://github.com/owner/repo
-		clean = strings.TrimPrefix(clean, "https://")
-		clean = strings.TrimPrefix(clean, "http://")
-		parts = strings.Split(clean, "/")
+	u, err := git.ParseURL(gitURL)
+	if err != nil {
+		return nil
 	}
 
-	if len(parts) < 3 {
+	clean := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	parts := strings.Split(clean, "/")
+	if len(parts) < 2 {
 		return nil
 	}
 
 	return &GitURL{
-		Host:  parts[0],
-		Owner: parts[1],
-		Repo:  parts[2],
+		Host:  u.Hostname(),
+		Owner: parts[0],
+		Repo:  parts[1],
 	}
 }
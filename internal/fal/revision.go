@@ -0,0 +1,69 @@
+package fal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Revision struct {
+	Revision    string `json:"revision"`
+	CreatedAt   string `json:"created_at"`
+	MachineType string `json:"machine_type"`
+	GitCommit   string `json:"git_commit"`
+
+	// Active is set when the revision is the one the app is currently served
+	// from.
+	Active bool `json:"-"`
+}
+
+// ListRevisions returns the revisions of app, newest first.
+func (f *Client) ListRevisions(ctx context.Context, app string) ([]*Revision, error) {
+	c, err := f.runFal(ctx, "apps", "list-rev", app, "--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error running fal apps list-rev in uv environment: %w: %s", err, readAll(c))
+	}
+
+	lines := readLines(c)
+
+	var result struct {
+		Revisions []*Revision `json:"revisions"`
+	}
+	if err := decodeJSONDocument(lines, &result); err != nil {
+		return nil, fmt.Errorf("error decoding revisions: %w: %s", err, strings.Join(lines, "\n"))
+	}
+
+	apps, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range apps {
+		if a.Alias != app {
+			continue
+		}
+		for _, r := range result.Revisions {
+			r.Active = r.Revision == a.Revision
+		}
+	}
+
+	sort.SliceStable(result.Revisions, func(i, j int) bool {
+		return createdAt(result.Revisions[i]).After(createdAt(result.Revisions[j]))
+	})
+	return result.Revisions, nil
+}
+
+func createdAt(r *Revision) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, r.CreatedAt)
+	return t
+}
+
+// SetRevision points app at revision, e.g. to roll back a failed deployment.
+func (f *Client) SetRevision(ctx context.Context, app, revision string) error {
+	out, err := f.outputFal(ctx, "apps", "set-rev", revision, app)
+	if err != nil {
+		return fmt.Errorf("error running fal apps set-rev: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package fal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
+)
+
+// workspace is a checkout of a repository at a single commit. Apps deployed
+// from the same repository and commit share one workspace, and apps in the
+// same project of it share one synced virtual environment.
+type workspace struct {
+	mu     sync.Mutex
+	path   string
+	cloned bool
+	// synced holds the lockfile hash of each synced project, as committed
+	// rather than as the sync may have rewritten it.
+	synced map[string]string
+}
+
+// checkout returns the path of project in the workspace for the commit the
+// cloned branch points at, synced by the client's runner with env, and the
+// hash of its committed lockfile. The project's environment is synced the
+// first time it is asked for.
+func (f *Client) checkout(ctx context.Context, gitClient *git.Client, repo string, env map[string]string, opts *DeployOpts) (string, string, error) {
+	ws, project, err := f.workspace(ctx, gitClient, repo, opts)
+	if err != nil {
+		return "", "", err
+	}
+	defer ws.mu.Unlock()
+
+	path := filepath.Join(ws.path, project)
+	if hash, ok := ws.synced[project]; ok {
+		return path, hash, nil
+	}
+
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("project path %s is not a directory in the repository", project)
+	}
+
+	hash, err := f.hashLockfile(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	r, err := f.projectRunner(path, env, opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	// other deployments use the workspace as soon as it is ready, so the
+	// sync has finished when it returns
+	out, err := r.Sync(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("error syncing virtual environment: %w: %s", err, out)
+	}
+
+	ws.synced[project] = hash
+	return path, hash, nil
+}
+
+// workspace returns the locked workspace for the commit the branch of opts
+// points at and the project of opts in it. The repository is cloned the first
+// time it is asked for.
+func (f *Client) workspace(ctx context.Context, gitClient *git.Client, repo string, opts *DeployOpts) (*workspace, string, error) {
+	project := opts.Path
+	if project == "" {
+		project = "."
+	}
+	if !filepath.IsLocal(project) {
+		return nil, "", fmt.Errorf("project path %s must be relative to the repository root and stay inside it", project)
+	}
+
+	clone := &git.CloneOpts{
+		Branch:      opts.Branch,
+		Depth:       opts.Depth,
+		SparsePaths: opts.SparsePaths,
+		Submodules:  opts.Submodules,
+		LFS:         opts.LFS,
+		Verify:      opts.Verify,
+	}
+	commit, err := gitClient.Resolve(ctx, repo, clone.Branch)
+	if err != nil {
+		return nil, "", fmt.Errorf("error resolving git reference: %w", err)
+	}
+	clone.Commit = commit
+
+	f.mu.Lock()
+	if f.workspaces == nil {
+		f.workspaces = map[string]*workspace{}
+	}
+	key := fmt.Sprintf("%s@%s depth=%d sparse=%q submodules=%t lfs=%t index=%s lockfile=%s", repo, commit, clone.Depth, clone.SparsePaths, clone.Submodules, clone.LFS, f.pythonIndexFor(opts), opts.LockfileMode)
+	ws, ok := f.workspaces[key]
+	if !ok {
+		name := "repo"
+		if u := parseGitURL(repo); u != nil {
+			name = u.Repo
+		}
+		ws = &workspace{
+			path:   fmt.Sprintf("%s/%s-%s-%d", f.dir, name, commit[:12], len(f.workspaces)),
+			synced: map[string]string{},
+		}
+		f.workspaces[key] = ws
+	}
+	f.mu.Unlock()
+
+	ws.mu.Lock()
+	if err := f.clone(ctx, gitClient, ws, repo, clone); err != nil {
+		ws.mu.Unlock()
+		return nil, "", err
+	}
+	return ws, project, nil
+}
+
+// clone clones the repository into ws unless it already is.
+func (f *Client) clone(ctx context.Context, gitClient *git.Client, ws *workspace, repo string, clone *git.CloneOpts) error {
+	if ws.cloned {
+		// apps sharing the workspace may trust different keys
+		if clone.Verify != nil {
+			if err := gitClient.Verify(ctx, ws.path, repo, clone.Verify); err != nil {
+				return fmt.Errorf("error verifying git repo: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// start over from a clean directory if an earlier attempt failed
+	if err := os.RemoveAll(ws.path); err != nil {
+		return err
+	}
+	if err := gitClient.Clone(ctx, ws.path, repo, clone); err != nil {
+		return fmt.Errorf("error cloning git repo: %w", err)
+	}
+	ws.cloned = true
+	return nil
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-git/go-git/v6/plumbing/protocol"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	githttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/go-git/go-git/v6/storage"
+)
+
+// go-git has no per-operation client certificates, so the http(s) transports
+// are replaced by one that picks a client with the certificate carried by
+// clientCertAuth.
+func init() {
+	t := &clientCertTransport{
+		Transport: githttp.DefaultTransport,
+		clients:   map[[sha256.Size]byte]transport.Transport{},
+	}
+	transport.Register("http", t)
+	transport.Register("https", t)
+}
+
+// clientCertAuth authenticates like AuthMethod, presenting a TLS client
+// certificate as well.
+type clientCertAuth struct {
+	githttp.AuthMethod
+	cert tls.Certificate
+	key  [sha256.Size]byte
+}
+
+func (a *clientCertAuth) Name() string {
+	if a.AuthMethod == nil {
+		return "http-client-cert"
+	}
+	return a.AuthMethod.Name()
+}
+
+func (a *clientCertAuth) String() string {
+	if a.AuthMethod == nil {
+		return a.Name()
+	}
+	return a.AuthMethod.String()
+}
+
+func (a *clientCertAuth) SetAuth(r *http.Request) {
+	if a.AuthMethod != nil {
+		a.AuthMethod.SetAuth(r)
+	}
+}
+
+type clientCertTransport struct {
+	transport.Transport
+
+	mu      sync.Mutex
+	clients map[[sha256.Size]byte]transport.Transport
+}
+
+func (t *clientCertTransport) NewSession(st storage.Storer, ep *transport.Endpoint, auth transport.AuthMethod) (transport.Session, error) {
+	a, ok := auth.(*clientCertAuth)
+	if !ok {
+		return t.Transport.NewSession(st, ep, auth)
+	}
+
+	t.mu.Lock()
+	client, ok := t.clients[a.key]
+	if !ok {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = &tls.Config{Certificates: []tls.Certificate{a.cert}}
+		client = githttp.NewTransport(&githttp.TransportOptions{Client: &http.Client{Transport: tr}})
+		t.clients[a.key] = client
+	}
+	t.mu.Unlock()
+
+	return client.NewSession(st, ep, a.AuthMethod)
+}
+
+func (t *clientCertTransport) SupportedProtocols() []protocol.Version {
+	return t.Transport.SupportedProtocols()
+}
+
+// ParseClientCertificate parses a PEM encoded certificate and private key.
+func ParseClientCertificate(cert, key []byte) (tls.Certificate, error) {
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error parsing client certificate: %w", err)
+	}
+	return pair, nil
+}
+
+// authMethod returns the auth method to pass to go-git, carrying the client
+// certificate if there is one.
+func (c *Client) authMethod() (transport.AuthMethod, error) {
+	if len(c.auth.ClientCert) == 0 && len(c.auth.ClientKey) == 0 {
+		return c.auth.AuthMethod, nil
+	}
+
+	cert, err := ParseClientCertificate(c.auth.ClientCert, c.auth.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+	a := &clientCertAuth{
+		cert: cert,
+		key:  sha256.Sum256(append(append([]byte{}, c.auth.ClientCert...), c.auth.ClientKey...)),
+	}
+	if c.auth.AuthMethod != nil {
+		inner, ok := c.auth.AuthMethod.(githttp.AuthMethod)
+		if !ok {
+			return nil, fmt.Errorf("client certificates can only be used with http(s) authentication")
+		}
+		a.AuthMethod = inner
+	}
+	return a, nil
+}
@@ -0,0 +1,165 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	githttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	"golang.org/x/crypto/ssh"
+)
+
+// askpassScript answers git's credential prompts for the repository's host
+// only. The credentials are read from the environment so they are never
+// written to disk.
+const askpassScript = `#!/bin/sh
+case "$1" in
+*"//$FAL_GIT_HOST'"* | *"@$FAL_GIT_HOST'"*) ;;
+*) exit 1 ;;
+esac
+case "$1" in
+Username*) printf '%s\n' "$FAL_GIT_USERNAME" ;;
+*) printf '%s\n' "$FAL_GIT_PASSWORD" ;;
+esac
+`
+
+// CredentialEnv writes helpers into dir that let git subprocesses, like uv
+// fetching git dependencies, authenticate to the host of repoURL the way c
+// does. It returns the environment enabling them. dir holds secrets and
+// should be removed once the subprocesses are done.
+func (c *Client) CredentialEnv(dir, repoURL string) (map[string]string, error) {
+	u, err := ParseURL(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing url: %w", err)
+	}
+
+	env := map[string]string{
+		// fail instead of waiting for input nobody gives
+		"GIT_TERMINAL_PROMPT": "0",
+	}
+	switch u.Scheme {
+	case "http", "https":
+		err = c.httpCredentialEnv(env, dir, u.Scheme, u.Host)
+	case "ssh":
+		err = c.sshCredentialEnv(env, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func (c *Client) httpCredentialEnv(env map[string]string, dir, scheme, host string) error {
+	var username, password string
+	switch a := c.auth.AuthMethod.(type) {
+	case *githttp.BasicAuth:
+		username, password = a.Username, a.Password
+	case *githttp.TokenAuth:
+		// git only asks for basic credentials, which hosts like GitHub and
+		// GitLab accept tokens as
+		username, password = "x-access-token", a.Token
+	}
+	if username != "" || password != "" {
+		askpass := filepath.Join(dir, "askpass.sh")
+		if err := os.WriteFile(askpass, []byte(askpassScript), 0o700); err != nil {
+			return err
+		}
+		env["GIT_ASKPASS"] = askpass
+		env["FAL_GIT_HOST"] = host
+		env["FAL_GIT_USERNAME"] = username
+		env["FAL_GIT_PASSWORD"] = password
+	}
+
+	// TLS settings only apply to the repository's host, unlike GIT_SSL_*
+	prefix := fmt.Sprintf("http.%s://%s/.", scheme, host)
+	var config [][2]string
+	if len(c.auth.CABundle) > 0 {
+		caFile := filepath.Join(dir, "ca.pem")
+		if err := os.WriteFile(caFile, c.auth.CABundle, 0o600); err != nil {
+			return err
+		}
+		config = append(config, [2]string{prefix + "sslCAInfo", caFile})
+	}
+	if len(c.auth.ClientCert) > 0 {
+		certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+		if err := os.WriteFile(certFile, c.auth.ClientCert, 0o600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(keyFile, c.auth.ClientKey, 0o600); err != nil {
+			return err
+		}
+		config = append(config, [2]string{prefix + "sslCert", certFile}, [2]string{prefix + "sslKey", keyFile})
+	}
+	if c.auth.InsecureSkipTLS {
+		config = append(config, [2]string{prefix + "sslVerify", "false"})
+	}
+
+	if len(config) > 0 {
+		env["GIT_CONFIG_COUNT"] = strconv.Itoa(len(config))
+		for i, kv := range config {
+			env[fmt.Sprintf("GIT_CONFIG_KEY_%d", i)] = kv[0]
+			env[fmt.Sprintf("GIT_CONFIG_VALUE_%d", i)] = kv[1]
+		}
+	}
+	return nil
+}
+
+func (c *Client) sshCredentialEnv(env map[string]string, dir string) error {
+	command := []string{"ssh"}
+
+	if len(c.auth.PrivateKey) > 0 {
+		key, err := unencryptedPrivateKey(c.auth.PrivateKey, c.auth.Passphrase)
+		if err != nil {
+			return fmt.Errorf("error decrypting ssh private key: %w", err)
+		}
+		keyFile := filepath.Join(dir, "id")
+		if err := os.WriteFile(keyFile, key, 0o600); err != nil {
+			return err
+		}
+		command = append(command, "-i", shellQuote(keyFile), "-o", "IdentitiesOnly=yes")
+	}
+
+	if c.auth.KnownHosts != "" {
+		knownHosts := filepath.Join(dir, "known_hosts")
+		if err := os.WriteFile(knownHosts, []byte(c.auth.KnownHosts+"\n"), 0o600); err != nil {
+			return err
+		}
+		command = append(command, "-o", "UserKnownHostsFile="+shellQuote(knownHosts), "-o", "StrictHostKeyChecking=yes")
+	}
+
+	// the ssh agent is reached through the inherited SSH_AUTH_SOCK
+	if len(command) > 1 {
+		env["GIT_SSH_COMMAND"] = strings.Join(command, " ")
+	}
+	return nil
+}
+
+// unencryptedPrivateKey returns key, a PEM encoded SSH private key, without
+// its passphrase, as ssh could only ask for it interactively.
+func unencryptedPrivateKey(key []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return key, nil
+	}
+
+	raw, err := ssh.ParseRawPrivateKeyWithPassphrase(key, []byte(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := raw.(*ed25519.PrivateKey); ok {
+		raw = *k
+	}
+	block, err := ssh.MarshalPrivateKey(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// shellQuote quotes s for GIT_SSH_COMMAND, which git runs with a shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -2,15 +2,62 @@ package git
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
 
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
 	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage/memory"
 )
 
+var commitHashRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 type AuthOpts struct {
 	AuthMethod      transport.AuthMethod
 	InsecureSkipTLS bool
 	CABundle        []byte
+	Proxy           *Proxy
+	// ClientCert and ClientKey are the PEM encoded TLS client certificate
+	// presented to http(s) remotes.
+	ClientCert []byte
+	ClientKey  []byte
+	// PrivateKey, Passphrase and KnownHosts are the SSH settings behind
+	// AuthMethod, which git subprocesses need to authenticate the same way.
+	PrivateKey []byte
+	Passphrase string
+	KnownHosts string
+}
+
+type CloneOpts struct {
+	// Branch is the branch to check out, the remote's HEAD if empty. A full
+	// commit hash checks out that commit instead.
+	Branch string
+	// Commit is the hash Branch was resolved to. If set, it is checked out
+	// even if the branch moved on before it was cloned.
+	Commit string
+	// Depth limits the history fetched to that many commits, all of it if
+	// zero.
+	Depth int
+	// SparsePaths limits the checkout to these directories, relative to the
+	// repository root, and the files at the root. Everything is checked out
+	// if empty.
+	SparsePaths []string
+	// Submodules checks out submodules recursively.
+	Submodules bool
+	// LFS replaces Git LFS pointers with the objects they point to.
+	LFS bool
+	// Verify checks the signature of the checked-out commit before anything
+	// else is fetched, if set.
+	Verify *SignatureOpts
 }
 
 type Client struct {
@@ -23,13 +70,347 @@ func New(authOpts *AuthOpts) *Client {
 	}
 }
 
-func (c *Client) Clone(ctx context.Context, path, repoURL string) (err error) {
-	_, err = git.PlainCloneContext(ctx, path, &git.CloneOptions{
+// Resolve returns the commit branch points at in the remote repository, or
+// the one HEAD points at if branch is empty.
+func (c *Client) Resolve(ctx context.Context, repoURL, branch string) (string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	proxyOpts, err := c.proxyOptions(repoURL)
+	if err != nil {
+		return "", err
+	}
+	auth, err := c.authMethod()
+	if err != nil {
+		return "", err
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth:            auth,
+		InsecureSkipTLS: c.auth.InsecureSkipTLS,
+		CABundle:        c.caBundle(),
+		ProxyOptions:    proxyOpts,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if IsCommitHash(branch) {
+		return branch, nil
+	}
+
+	name := plumbing.HEAD
+	if branch != "" {
+		name = plumbing.NewBranchReferenceName(branch)
+	}
+
+	// HEAD may be advertised as a symbolic reference, follow it to its target
+	for i := 0; i < 2; i++ {
+		for _, ref := range refs {
+			if ref.Name() != name {
+				continue
+			}
+			if ref.Type() == plumbing.HashReference {
+				return ref.Hash().String(), nil
+			}
+			name = ref.Target()
+			break
+		}
+	}
+	return "", fmt.Errorf("reference %s not found in %s", name, repoURL)
+}
+
+func (c *Client) Clone(ctx context.Context, path, repoURL string, opts *CloneOpts) error {
+	var repo *git.Repository
+	var err error
+	if IsCommitHash(opts.Branch) {
+		repo, err = c.clonePinned(ctx, path, repoURL, opts)
+	} else {
+		repo, err = c.cloneBranch(ctx, path, repoURL, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Verify != nil {
+		if err := c.verify(ctx, repo, repoURL, opts.Verify); err != nil {
+			return err
+		}
+	}
+
+	return c.populate(ctx, repo, path, repoURL, opts)
+}
+
+// cloneBranch clones the tip of opts.Branch, or of the remote's HEAD.
+func (c *Client) cloneBranch(ctx context.Context, path, repoURL string, opts *CloneOpts) (*git.Repository, error) {
+	proxyOpts, err := c.proxyOptions(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := c.authMethod()
+	if err != nil {
+		return nil, err
+	}
+	cloneOpts := &git.CloneOptions{
 		URL:             repoURL,
-		Auth:            c.auth.AuthMethod,
-		Depth:           1,
+		Auth:            auth,
+		Depth:           opts.Depth,
+		NoCheckout:      true,
 		InsecureSkipTLS: c.auth.InsecureSkipTLS,
-		CABundle:        c.auth.CABundle,
+		CABundle:        c.caBundle(),
+		ProxyOptions:    proxyOpts,
+	}
+	if opts.Branch != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+		cloneOpts.SingleBranch = true
+	}
+	repo, err := git.PlainCloneContext(ctx, path, cloneOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	hash := head.Hash()
+	if opts.Commit != "" && opts.Commit != hash.String() {
+		// the branch moved since it was resolved, so go back to the commit
+		// the caller expects, which a shallow clone may not include
+		hash = plumbing.NewHash(opts.Commit)
+		if _, err := repo.CommitObject(hash); err != nil {
+			if err := c.fetchCommit(ctx, repo, repoURL, hash, opts.Depth); err != nil {
+				return nil, err
+			}
+		}
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, hash)); err != nil {
+			return nil, err
+		}
+	}
+	return repo, checkout(repo, hash, opts.SparsePaths)
+}
+
+// clonePinned clones the commit opts.Branch is the hash of.
+func (c *Client) clonePinned(ctx context.Context, path, repoURL string, opts *CloneOpts) (*git.Repository, error) {
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := plumbing.NewHash(opts.Branch)
+	if err := c.fetchCommit(ctx, repo, repoURL, hash, opts.Depth); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, hash)); err != nil {
+		return nil, err
+	}
+	return repo, checkout(repo, hash, opts.SparsePaths)
+}
+
+// ReadFile returns the content of the file at name, relative to the
+// repository root, in commit of the remote repository, or an error wrapping
+// fs.ErrNotExist if there is none. Only the commit is fetched into a
+// temporary repository in dir, without a checkout, submodules or Git LFS
+// objects, and removed again.
+func (c *Client) ReadFile(ctx context.Context, dir, repoURL, commit, name string) ([]byte, error) {
+	path, err := os.MkdirTemp(dir, "read-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(path)
+
+	repo, err := git.PlainInit(path, true)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
 	})
-	return
+	if err != nil {
+		return nil, err
+	}
+
+	hash := plumbing.NewHash(commit)
+	if err := c.fetchCommit(ctx, repo, repoURL, hash, 1); err != nil {
+		return nil, err
+	}
+	obj, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	file, err := obj.File(name)
+	if errors.Is(err, object.ErrFileNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// fetchCommit fetches the commit hash from the origin remote of repo. The
+// commit is fetched directly if the remote allows it. Otherwise the branches
+// are fetched with more and more history until it turns up.
+func (c *Client) fetchCommit(ctx context.Context, repo *git.Repository, repoURL string, hash plumbing.Hash, depth int) error {
+	proxyOpts, err := c.proxyOptions(repoURL)
+	if err != nil {
+		return err
+	}
+	auth, err := c.authMethod()
+	if err != nil {
+		return err
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+
+	fetch := func(refSpec config.RefSpec, depth int) error {
+		err := remote.FetchContext(ctx, &git.FetchOptions{
+			RefSpecs:        []config.RefSpec{refSpec},
+			Depth:           depth,
+			Tags:            plumbing.NoTags,
+			Auth:            auth,
+			InsecureSkipTLS: c.auth.InsecureSkipTLS,
+			CABundle:        c.caBundle(),
+			ProxyOptions:    proxyOpts,
+		})
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil
+		}
+		return err
+	}
+
+	err = fetch(config.RefSpec(fmt.Sprintf("%s:refs/remotes/%s/pinned", hash, git.DefaultRemoteName)), depth)
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		err = deepenUntil(repo, hash, depth, fetch)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching commit %s: %w", hash, err)
+	}
+	return nil
+}
+
+// deepenUntil fetches the branches of the remote, doubling the depth until
+// hash is among the fetched commits or the whole history is.
+func deepenUntil(repo *git.Repository, hash plumbing.Hash, depth int, fetch func(config.RefSpec, int) error) error {
+	refSpec := config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", git.DefaultRemoteName))
+	for {
+		if err := fetch(refSpec, depth); err != nil {
+			return err
+		}
+		if _, err := repo.CommitObject(hash); err == nil {
+			return nil
+		}
+
+		shallow, err := repo.Storer.Shallow()
+		if err != nil {
+			return err
+		}
+		if depth == 0 || len(shallow) == 0 {
+			return fmt.Errorf("commit is not on any branch")
+		}
+		depth *= 2
+	}
+}
+
+// checkout checks out hash into the worktree of repo. If paths is not empty,
+// only files at the repository root and in the directories of paths are,
+// like in git's sparse-checkout cone mode.
+func checkout(repo *git.Repository, hash plumbing.Hash, paths []string) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return wt.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset})
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	var dirs []string
+	for _, p := range paths {
+		p = strings.Trim(p, "/")
+		entry, err := tree.FindEntry(p)
+		if err != nil || entry.Mode != filemode.Dir {
+			return fmt.Errorf("sparse path %s is not a directory in commit %s", p, hash)
+		}
+		dirs = append(dirs, p+"/")
+	}
+
+	// go-git's SparseDirs are prefixes of file paths, which cannot include
+	// a root file like Makefile without Makefile.d/ too. So the index is
+	// filled first and the entries outside the cone are skipped by hand
+	// before the worktree is written, which leaves skipped entries alone.
+	if err := wt.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset}); err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, e := range idx.Entries {
+		e.SkipWorktree = !inSparseCone(e.Name, dirs)
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return err
+	}
+	return wt.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset})
+}
+
+// inSparseCone reports whether the file at name is checked out by a sparse
+// checkout of dirs, which end in a slash: it is at the repository root or in
+// one of dirs.
+func inSparseCone(name string, dirs []string) bool {
+	if !strings.Contains(name, "/") {
+		return true
+	}
+	for _, dir := range dirs {
+		if strings.HasPrefix(name, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCommitHash reports whether ref is a full commit hash rather than a branch
+// name.
+func IsCommitHash(ref string) bool {
+	return commitHashRe.MatchString(ref)
+}
+
+// populate fetches what a plain clone of the repository at path leaves out.
+func (c *Client) populate(ctx context.Context, repo *git.Repository, path, repoURL string, opts *CloneOpts) error {
+	if opts.Submodules {
+		if err := c.updateSubmodules(ctx, repo, path, repoURL, opts); err != nil {
+			return fmt.Errorf("error updating submodules: %w", err)
+		}
+	}
+	if opts.LFS {
+		if err := c.pullLFS(ctx, path, repoURL); err != nil {
+			return fmt.Errorf("error fetching git lfs objects: %w", err)
+		}
+	}
+	return nil
 }
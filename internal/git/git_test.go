@@ -0,0 +1,126 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/acctest"
+)
+
+const testRepoPath = "fal-ai/demo.git"
+
+func TestCloneResolvedCommit(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		depth  int
+	}{
+		{name: "remote HEAD shallow", depth: 1},
+		{name: "branch shallow", branch: acctest.DefaultBranch, depth: 1},
+		{name: "branch full history", branch: acctest.DefaultBranch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := acctest.NewRepository(t, map[string]string{"app.py": "v1\n"})
+			srv := acctest.NewHTTPServer(t, map[string]*acctest.Repository{testRepoPath: fixture})
+			url := srv.RepoURL(testRepoPath)
+
+			c := New(&AuthOpts{})
+			commit, err := c.Resolve(context.Background(), url, tt.branch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the branch moves on between resolving and cloning it
+			fixture.Commit(t, "v2", map[string]string{"app.py": "v2\n"})
+
+			dir := t.TempDir()
+			err = c.Clone(context.Background(), dir, url, &CloneOpts{Branch: tt.branch, Commit: commit, Depth: tt.depth})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repo, err := git.PlainOpen(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			head, err := repo.Head()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if head.Hash().String() != commit {
+				t.Errorf("expected HEAD at %s, got %s", commit, head.Hash())
+			}
+			if b, err := os.ReadFile(filepath.Join(dir, "app.py")); err != nil || string(b) != "v1\n" {
+				t.Errorf("expected app.py of the resolved commit, got %q, %v", b, err)
+			}
+		})
+	}
+}
+
+func TestCloneSparsePaths(t *testing.T) {
+	fixture := acctest.NewRepository(t, map[string]string{
+		"Makefile":                "all:\n",
+		"Makefile.d/rules.mk":     "rules\n",
+		"README":                  "readme\n",
+		"README_assets/logo.txt":  "logo\n",
+		"services/app/app.py":     "app\n",
+		"services/app2/app.py":    "app2\n",
+		"services/other/other.py": "other\n",
+		"libs/common/common.py":   "common\n",
+	})
+	srv := acctest.NewHTTPServer(t, map[string]*acctest.Repository{testRepoPath: fixture})
+
+	dir := t.TempDir()
+	err := New(&AuthOpts{}).Clone(context.Background(), dir, srv.RepoURL(testRepoPath), &CloneOpts{
+		Depth:       1,
+		SparsePaths: []string{"services/app", "/libs/common/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Makefile", "README", "services/app/app.py", "libs/common/common.py"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be checked out: %s", name, err)
+		}
+	}
+	for _, name := range []string{"Makefile.d", "README_assets", "services/app2", "services/other"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected %s not to be checked out", name)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	fixture := acctest.NewRepository(t, map[string]string{"services/app/uv.lock": "v1\n"})
+	commit := fixture.Head(t)
+	fixture.Commit(t, "v2", map[string]string{"services/app/uv.lock": "v2\n"})
+	srv := acctest.NewHTTPServer(t, map[string]*acctest.Repository{testRepoPath: fixture})
+
+	c := New(&AuthOpts{})
+	dir := t.TempDir()
+	b, err := c.ReadFile(context.Background(), dir, srv.RepoURL(testRepoPath), commit, "services/app/uv.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "v1\n" {
+		t.Errorf("expected uv.lock of the commit, got %q", b)
+	}
+
+	_, err = c.ReadFile(context.Background(), dir, srv.RepoURL(testRepoPath), commit, "uv.lock")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing file to be reported as not existing, got %v", err)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("expected the fetched repositories to be removed, got %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyError is returned when an SSH server presents a host key that does
+// not match the configured known_hosts or fingerprint.
+type HostKeyError struct {
+	Host        string
+	Fingerprint string
+	Reason      string
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("host key verification failed for %s: %s (server presented %s)", e.Host, e.Reason, e.Fingerprint)
+}
+
+// HostKeyCallback returns a callback that only accepts host keys listed in
+// knownHosts, given in known_hosts format, and matching fingerprint, given
+// like ssh-keygen prints it. Empty values are not checked, and nil is
+// returned if neither is set.
+func HostKeyCallback(knownHosts, fingerprint string) (ssh.HostKeyCallback, error) {
+	if knownHosts == "" && fingerprint == "" {
+		return nil, nil
+	}
+
+	var known ssh.HostKeyCallback
+	if knownHosts != "" {
+		var err error
+		known, err = knownHostsCallback(knownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing known_hosts: %w", err)
+		}
+	}
+
+	if fingerprint != "" && !strings.HasPrefix(fingerprint, "SHA256:") {
+		return nil, fmt.Errorf("host key fingerprint %q is not a SHA256 fingerprint", fingerprint)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		presented := ssh.FingerprintSHA256(key)
+
+		if fingerprint != "" && presented != fingerprint {
+			return &HostKeyError{Host: hostname, Fingerprint: presented, Reason: "fingerprint does not match " + fingerprint}
+		}
+
+		if known == nil {
+			return nil
+		}
+		err := known(hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &keyErr) && len(keyErr.Want) > 0:
+			return &HostKeyError{Host: hostname, Fingerprint: presented, Reason: "host key does not match known_hosts"}
+		case errors.As(err, &keyErr):
+			return &HostKeyError{Host: hostname, Fingerprint: presented, Reason: "host is not in known_hosts"}
+		default:
+			return err
+		}
+	}, nil
+}
+
+// knownHostsCallback parses known_hosts content. knownhosts only reads files,
+// so it goes through a temporary one.
+func knownHostsCallback(content string) (ssh.HostKeyCallback, error) {
+	f, err := os.CreateTemp("", "known_hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(content + "\n"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return knownhosts.New(f.Name())
+}
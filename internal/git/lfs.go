@@ -0,0 +1,390 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	githttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v6/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+	lfsMediaType      = "application/vnd.git-lfs+json"
+
+	// pointer files are small, anything larger is regular content
+	maxLFSPointerSize = 1024
+)
+
+type lfsPointer struct {
+	path string
+	oid  string
+	size int64
+}
+
+// lfsAction is where to get an LFS object from, as returned by the batch API
+// and git-lfs-authenticate.
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+type lfsBatchObject struct {
+	Oid     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *lfsAction `json:"download"`
+	} `json:"actions"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// pullLFS replaces the Git LFS pointer files checked out in dir with the
+// objects they point to, downloaded through the LFS batch API of repoURL.
+func (c *Client) pullLFS(ctx context.Context, dir, repoURL string) error {
+	pointers, err := findLFSPointers(dir)
+	if err != nil {
+		return err
+	}
+	if len(pointers) == 0 {
+		return nil
+	}
+
+	client, err := c.httpClient()
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := c.lfsEndpoint(ctx, repoURL)
+	if err != nil {
+		return err
+	}
+
+	objects, err := c.lfsBatch(ctx, client, endpoint, pointers)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pointers {
+		obj, ok := objects[p.oid]
+		if !ok {
+			return fmt.Errorf("lfs object %s of %s is missing from the batch response", p.oid, p.path)
+		}
+		if obj.Error != nil {
+			return fmt.Errorf("lfs object %s of %s: %d %s", p.oid, p.path, obj.Error.Code, obj.Error.Message)
+		}
+		if obj.Actions.Download == nil {
+			return fmt.Errorf("lfs object %s of %s cannot be downloaded", p.oid, p.path)
+		}
+		if err := c.lfsDownload(ctx, client, endpoint, obj.Actions.Download, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findLFSPointers returns the LFS pointer files in dir, skipping nested
+// repositories.
+func findLFSPointers(dir string) ([]*lfsPointer, error) {
+	var pointers []*lfsPointer
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if path != dir {
+				if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxLFSPointerSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if p := parseLFSPointer(content); p != nil {
+			p.path = path
+			pointers = append(pointers, p)
+		}
+		return nil
+	})
+	return pointers, err
+}
+
+func parseLFSPointer(content []byte) *lfsPointer {
+	if !bytes.HasPrefix(content, []byte(lfsPointerVersion+"\n")) {
+		return nil
+	}
+
+	p := &lfsPointer{size: -1}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		switch key {
+		case "oid":
+			p.oid = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil
+			}
+			p.size = size
+		}
+	}
+	if p.oid == "" || p.size < 0 {
+		return nil
+	}
+	return p
+}
+
+// lfsEndpoint returns the LFS server of repoURL. For SSH remotes it is asked
+// for with git-lfs-authenticate, which also hands out the headers to use.
+func (c *Client) lfsEndpoint(ctx context.Context, repoURL string) (*lfsAction, error) {
+	u, err := ParseURL(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		href := strings.TrimSuffix(u.String(), "/")
+		if !strings.HasSuffix(href, ".git") {
+			href += ".git"
+		}
+		return &lfsAction{Href: href + "/info/lfs"}, nil
+	case "ssh":
+		return c.lfsAuthenticate(ctx, u)
+	default:
+		return nil, fmt.Errorf("git lfs is not supported for %s urls", u.Scheme)
+	}
+}
+
+func (c *Client) lfsAuthenticate(ctx context.Context, u *url.URL) (*lfsAction, error) {
+	auth, ok := c.auth.AuthMethod.(gitssh.AuthMethod)
+	if !ok {
+		return nil, fmt.Errorf("git lfs over ssh requires ssh authentication")
+	}
+	config, err := auth.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	conn, err := c.dial(ctx, u.String(), addr)
+	if err != nil {
+		return nil, err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	output, err := session.Output(fmt.Sprintf("git-lfs-authenticate '%s' download", strings.TrimPrefix(u.Path, "/")))
+	if err != nil {
+		return nil, fmt.Errorf("error running git-lfs-authenticate: %w", err)
+	}
+
+	var action lfsAction
+	if err := json.Unmarshal(output, &action); err != nil {
+		return nil, fmt.Errorf("error decoding git-lfs-authenticate response: %w", err)
+	}
+	return &action, nil
+}
+
+func (c *Client) lfsBatch(ctx context.Context, client *http.Client, endpoint *lfsAction, pointers []*lfsPointer) (map[string]*lfsBatchObject, error) {
+	type object struct {
+		Oid  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+	request := struct {
+		Operation string   `json:"operation"`
+		Transfers []string `json:"transfers"`
+		Objects   []object `json:"objects"`
+	}{
+		Operation: "download",
+		Transfers: []string{"basic"},
+	}
+	for _, p := range pointers {
+		request.Objects = append(request.Objects, object{Oid: p.oid, Size: p.size})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Href+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	c.setLFSAuth(req, endpoint, endpoint)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("lfs batch request failed with status %d: %s", resp.StatusCode, msg)
+	}
+
+	var response struct {
+		Objects []*lfsBatchObject `json:"objects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error decoding lfs batch response: %w", err)
+	}
+
+	objects := make(map[string]*lfsBatchObject, len(response.Objects))
+	for _, o := range response.Objects {
+		objects[o.Oid] = o
+	}
+	return objects, nil
+}
+
+func (c *Client) lfsDownload(ctx context.Context, client *http.Client, endpoint, action *lfsAction, p *lfsPointer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, action.Href, nil)
+	if err != nil {
+		return err
+	}
+	c.setLFSAuth(req, endpoint, action)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lfs object %s of %s: download failed with status %d", p.oid, p.path, resp.StatusCode)
+	}
+
+	// write next to the pointer and only replace it once the object checks out
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), ".lfs-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if err != nil {
+		return fmt.Errorf("lfs object %s of %s: %w", p.oid, p.path, err)
+	}
+	if n != p.size || hex.EncodeToString(h.Sum(nil)) != p.oid {
+		return fmt.Errorf("lfs object %s of %s does not match its pointer", p.oid, p.path)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path)
+}
+
+// setLFSAuth applies the headers of action to req. Without an Authorization
+// header among them, requests to the LFS server's host are authenticated like
+// the git remote.
+func (c *Client) setLFSAuth(req *http.Request, endpoint, action *lfsAction) {
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("Authorization") != "" {
+		return
+	}
+
+	u, err := url.Parse(endpoint.Href)
+	if err != nil || u.Host != req.URL.Host {
+		return
+	}
+	if auth, ok := c.auth.AuthMethod.(githttp.AuthMethod); ok {
+		auth.SetAuth(req)
+	}
+}
+
+func (c *Client) httpClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.auth.InsecureSkipTLS,
+	}
+	if len(c.auth.ClientCert) > 0 || len(c.auth.ClientKey) > 0 {
+		cert, err := ParseClientCertificate(c.auth.ClientCert, c.auth.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if caBundle := c.caBundle(); len(caBundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("error parsing certificate authority")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = c.httpProxy
+	return &http.Client{Transport: transport}, nil
+}
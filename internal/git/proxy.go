@@ -0,0 +1,96 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// Proxy routes git traffic through an HTTP(S) or SOCKS5 proxy.
+type Proxy struct {
+	URL string
+	// NoProxy lists the hosts reached directly, in the NO_PROXY format.
+	NoProxy string
+	// CABundle is trusted in addition to the system roots, for proxies that
+	// intercept TLS.
+	CABundle []byte
+}
+
+// forURL returns the proxy to reach rawURL through, nil if it is reached
+// directly. SSH connections can only be tunneled through SOCKS5 proxies.
+func (p *Proxy) forURL(rawURL string) (*url.URL, error) {
+	if p == nil || p.URL == "" {
+		return nil, nil
+	}
+
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing url: %w", err)
+	}
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy url: %w", err)
+	}
+	if u.Scheme == "ssh" && proxyURL.Scheme != "socks5" && proxyURL.Scheme != "socks5h" {
+		return nil, nil
+	}
+
+	// httpproxy only knows http(s) URLs, which is all NO_PROXY matching
+	// needs
+	target := &url.URL{Scheme: "https", Host: u.Host}
+	config := &httpproxy.Config{HTTPSProxy: p.URL, NoProxy: p.NoProxy}
+	return config.ProxyFunc()(target)
+}
+
+// proxyOptions returns the go-git proxy options for rawURL.
+func (c *Client) proxyOptions(rawURL string) (transport.ProxyOptions, error) {
+	proxyURL, err := c.auth.Proxy.forURL(rawURL)
+	if err != nil || proxyURL == nil {
+		return transport.ProxyOptions{}, err
+	}
+	return transport.ProxyOptions{URL: proxyURL.String()}, nil
+}
+
+// caBundle returns the certificates trusted in addition to the system roots.
+func (c *Client) caBundle() []byte {
+	if c.auth.Proxy == nil || len(c.auth.Proxy.CABundle) == 0 {
+		return c.auth.CABundle
+	}
+	bundle := append([]byte{}, c.auth.CABundle...)
+	bundle = append(bundle, '\n')
+	return append(bundle, c.auth.Proxy.CABundle...)
+}
+
+// httpProxy is the Proxy of the client's http.Transport.
+func (c *Client) httpProxy(req *http.Request) (*url.URL, error) {
+	return c.auth.Proxy.forURL(req.URL.String())
+}
+
+// dial connects to addr, the host:port of rawURL, through the proxy if there
+// is one.
+func (c *Client) dial(ctx context.Context, rawURL, addr string) (net.Conn, error) {
+	proxyURL, err := c.auth.Proxy.forURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	}
+
+	dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	ctxDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("proxy %s does not support dialing with a context", proxyURL.Redacted())
+	}
+	return ctxDialer.DialContext(ctx, "tcp", addr)
+}
@@ -0,0 +1,336 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"path"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	pgpSignaturePrefix = "-----BEGIN PGP SIGNATURE-----"
+	sshSignaturePrefix = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureSuffix = "-----END SSH SIGNATURE-----"
+
+	// sshSignatureNamespace is the namespace git signs commits and tags in.
+	sshSignatureNamespace = "git"
+)
+
+// SignatureOpts are the keys the checked-out commit must be signed with.
+type SignatureOpts struct {
+	// GPGKeys is an armored keyring of trusted GPG public keys.
+	GPGKeys string
+	// SSHAllowedSigners lists trusted SSH public keys in the allowed_signers
+	// format of ssh-keygen.
+	SSHAllowedSigners string
+	// Tag is an annotated tag that must be signed by a trusted key too and
+	// point at the checked-out commit.
+	Tag string
+}
+
+// SignatureError is returned when the checked-out commit or tag is not signed
+// by a trusted key.
+type SignatureError struct {
+	// Object is the commit or tag that failed verification.
+	Object string
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("signature verification failed for %s: %s", e.Object, e.Reason)
+}
+
+// Validate parses the trusted keys of o.
+func (o *SignatureOpts) Validate() error {
+	if o.GPGKeys != "" {
+		if _, err := openpgp.ReadArmoredKeyRing(strings.NewReader(o.GPGKeys)); err != nil {
+			return fmt.Errorf("error parsing gpg keys: %w", err)
+		}
+	}
+	if _, err := parseAllowedSigners(o.SSHAllowedSigners); err != nil {
+		return fmt.Errorf("error parsing ssh allowed signers: %w", err)
+	}
+	return nil
+}
+
+// Verify checks that the commit checked out in the repository at dir, and
+// the tag of opts if set, are signed by a trusted key. The tag is fetched from
+// repoURL, as clones only include the checked-out branch.
+func (c *Client) Verify(ctx context.Context, dir, repoURL string, opts *SignatureOpts) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+	return c.verify(ctx, repo, repoURL, opts)
+}
+
+func (c *Client) verify(ctx context.Context, repo *git.Repository, repoURL string, opts *SignatureOpts) error {
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	name := "commit " + commit.Hash.String()
+	if err := verifySignature(name, commit.PGPSignature, encoded, opts); err != nil {
+		return err
+	}
+
+	if opts.Tag == "" {
+		return nil
+	}
+
+	tag, err := c.fetchTag(ctx, repo, repoURL, opts.Tag)
+	if err != nil {
+		return err
+	}
+
+	name = "tag " + opts.Tag
+	if tag.TargetType != plumbing.CommitObject || tag.Target != commit.Hash {
+		return &SignatureError{Object: name, Reason: fmt.Sprintf("tag points at %s instead of the deployed commit %s", tag.Target, commit.Hash)}
+	}
+
+	encoded = &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	return verifySignature(name, tag.PGPSignature, encoded, opts)
+}
+
+// fetchTag fetches the annotated tag name into repo. Lightweight tags cannot
+// be signed and are rejected.
+func (c *Client) fetchTag(ctx context.Context, repo *git.Repository, repoURL, name string) (*object.Tag, error) {
+	proxyOpts, err := c.proxyOptions(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := c.authMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.NewTagReferenceName(name)
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName:      git.DefaultRemoteName,
+		RefSpecs:        []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", refName, refName))},
+		Depth:           1,
+		Tags:            plumbing.NoTags,
+		Auth:            auth,
+		InsecureSkipTLS: c.auth.InsecureSkipTLS,
+		CABundle:        c.caBundle(),
+		ProxyOptions:    proxyOpts,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("error fetching tag %s: %w", name, err)
+	}
+
+	ref, err := repo.Reference(refName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving tag %s: %w", name, err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &SignatureError{Object: "tag " + name, Reason: "lightweight tags cannot be signed, use an annotated tag"}
+	}
+	return tag, err
+}
+
+// verifySignature checks that signature is a signature of payload by one of
+// the keys of opts.
+func verifySignature(name, signature string, payload plumbing.EncodedObject, opts *SignatureOpts) error {
+	r, err := payload.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	switch {
+	case signature == "":
+		return &SignatureError{Object: name, Reason: "not signed"}
+	case strings.HasPrefix(signature, pgpSignaturePrefix):
+		if opts.GPGKeys == "" {
+			return &SignatureError{Object: name, Reason: "signed with GPG but no GPG keys are trusted"}
+		}
+		keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(opts.GPGKeys))
+		if err != nil {
+			return fmt.Errorf("error parsing gpg keys: %w", err)
+		}
+		if _, err := openpgp.CheckArmoredDetachedSignature(keyring, r, strings.NewReader(signature), nil); err != nil {
+			return &SignatureError{Object: name, Reason: "not signed by a trusted GPG key: " + err.Error()}
+		}
+		return nil
+	case strings.HasPrefix(signature, sshSignaturePrefix):
+		if opts.SSHAllowedSigners == "" {
+			return &SignatureError{Object: name, Reason: "signed with SSH but no SSH keys are trusted"}
+		}
+		signers, err := parseAllowedSigners(opts.SSHAllowedSigners)
+		if err != nil {
+			return fmt.Errorf("error parsing ssh allowed signers: %w", err)
+		}
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(r); err != nil {
+			return err
+		}
+		if err := verifySSHSignature(signers, buf.Bytes(), signature); err != nil {
+			return &SignatureError{Object: name, Reason: err.Error()}
+		}
+		return nil
+	default:
+		return &SignatureError{Object: name, Reason: "signature format is not supported"}
+	}
+}
+
+// allowedSigner is an entry of an allowed_signers file.
+type allowedSigner struct {
+	key ssh.PublicKey
+	// namespaces the key may sign in, any if empty.
+	namespaces []string
+}
+
+// parseAllowedSigners parses allowed_signers content. Every line holds the
+// principals, options and public key of a signer, see ssh-keygen(1).
+// Principals are not matched against the commit author, like git does.
+func parseAllowedSigners(content string) ([]allowedSigner, error) {
+	var signers []allowedSigner
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// the rest of the line after the principals is the options and key
+		// like in authorized_keys
+		_, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing public key", i+1)
+		}
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		signer := allowedSigner{key: key}
+		for _, option := range options {
+			name, value, _ := strings.Cut(option, "=")
+			switch strings.ToLower(name) {
+			case "namespaces":
+				signer.namespaces = strings.Split(strings.Trim(value, `"`), ",")
+			case "cert-authority", "valid-after", "valid-before":
+				return nil, fmt.Errorf("line %d: option %s is not supported", i+1, name)
+			}
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// sshSignature is the blob of an armored SSH signature, see PROTOCOL.sshsig
+// of OpenSSH.
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// verifySSHSignature checks that armored is an SSH signature of message in
+// the git namespace by one of signers.
+func verifySSHSignature(signers []allowedSigner, message []byte, armored string) error {
+	armored = strings.TrimSpace(armored)
+	armored = strings.TrimSuffix(strings.TrimPrefix(armored, sshSignaturePrefix), sshSignatureSuffix)
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(armored), ""))
+	if err != nil {
+		return fmt.Errorf("malformed SSH signature: %w", err)
+	}
+
+	const magic = "SSHSIG"
+	if !bytes.HasPrefix(blob, []byte(magic)) {
+		return errors.New("malformed SSH signature: missing SSHSIG preamble")
+	}
+	var sig sshSignature
+	if err := ssh.Unmarshal(blob[len(magic):], &sig); err != nil {
+		return fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return fmt.Errorf("SSH signature version %d is not supported", sig.Version)
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("SSH signature is in namespace %q instead of %q", sig.Namespace, sshSignatureNamespace)
+	}
+
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if !trustedSSHKey(signers, key) {
+		return fmt.Errorf("not signed by a trusted SSH key: signed by %s", ssh.FingerprintSHA256(key))
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("SSH signature hash algorithm %q is not supported", sig.HashAlgorithm)
+	}
+	h.Write(message)
+
+	signed := append([]byte(magic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, h.Sum(nil)})...)
+
+	signature := new(ssh.Signature)
+	if err := ssh.Unmarshal(sig.Signature, signature); err != nil {
+		return fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if err := key.Verify(signed, signature); err != nil {
+		return fmt.Errorf("invalid SSH signature by %s: %w", ssh.FingerprintSHA256(key), err)
+	}
+	return nil
+}
+
+// trustedSSHKey reports whether key may sign in the git namespace. Like
+// ssh-keygen, namespaces are matched as patterns.
+func trustedSSHKey(signers []allowedSigner, key ssh.PublicKey) bool {
+	for _, s := range signers {
+		if !bytes.Equal(s.key.Marshal(), key.Marshal()) {
+			continue
+		}
+		if len(s.namespaces) == 0 {
+			return true
+		}
+		for _, pattern := range s.namespaces {
+			if ok, _ := path.Match(pattern, sshSignatureNamespace); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// updateSubmodules clones the submodules of repo and checks out the commits
+// recorded for them. go-git's own submodule support only passes on the auth
+// method, so submodules are cloned here with all of the client's options.
+func (c *Client) updateSubmodules(ctx context.Context, repo *git.Repository, dir, repoURL string, opts *CloneOpts) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	submodules, err := wt.Submodules()
+	if err != nil {
+		return err
+	}
+
+	// the submodules have sparse paths of their own
+	subOpts := *opts
+	subOpts.SparsePaths = nil
+
+	for _, sub := range submodules {
+		cfg := sub.Config()
+		if !inSparsePaths(cfg.Path, opts.SparsePaths) {
+			continue
+		}
+
+		status, err := sub.Status()
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+
+		subURL, err := submoduleURL(repoURL, cfg.URL)
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+
+		proxyOpts, err := c.proxyOptions(subURL)
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+		auth, err := c.authMethod()
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+
+		// the recorded commit is not necessarily the tip of a branch, so the
+		// submodule is cloned with its full history
+		subPath := filepath.Join(dir, filepath.FromSlash(cfg.Path))
+		subRepo, err := git.PlainCloneContext(ctx, subPath, &git.CloneOptions{
+			URL:             subURL,
+			Auth:            auth,
+			NoCheckout:      true,
+			InsecureSkipTLS: c.auth.InsecureSkipTLS,
+			CABundle:        c.caBundle(),
+			ProxyOptions:    proxyOpts,
+		})
+		if err != nil {
+			return fmt.Errorf("submodule %s: error cloning %s: %w", cfg.Name, subURL, err)
+		}
+
+		subWt, err := subRepo.Worktree()
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+		if err := subWt.Checkout(&git.CheckoutOptions{Hash: status.Expected, Force: true}); err != nil {
+			return fmt.Errorf("submodule %s: error checking out %s: %w", cfg.Name, status.Expected, err)
+		}
+
+		if err := c.populate(ctx, subRepo, subPath, subURL, &subOpts); err != nil {
+			return fmt.Errorf("submodule %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// inSparsePaths reports whether the submodule at p is checked out with paths.
+func inSparsePaths(p string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, dir := range paths {
+		if strings.HasPrefix(p+"/", strings.Trim(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// submoduleURL resolves a submodule URL from .gitmodules. Like git, relative
+// URLs are relative to the superproject's URL as if it were a directory.
+func submoduleURL(parent, sub string) (string, error) {
+	relative := strings.HasPrefix(sub, "./") || strings.HasPrefix(sub, "../")
+	if !relative {
+		parent = sub
+	}
+
+	u, err := ParseURL(parent)
+	if err != nil {
+		return "", fmt.Errorf("error parsing url: %w", err)
+	}
+	if relative {
+		u.Path = path.Join(u.Path, sub)
+	}
+	return u.String(), nil
+}
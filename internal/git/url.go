@@ -0,0 +1,30 @@
+package git
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// scpURL matches scp-like remotes such as git@github.com:owner/repo.git,
+// which git recognises by a colon before the first slash.
+var scpURL = regexp.MustCompile(`^(?:([^@/]+)@)?([^@/:]+):(.*)$`)
+
+// ParseURL parses a git remote URL. scp-like remotes are returned as the
+// equivalent ssh:// URL.
+func ParseURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		if m := scpURL.FindStringSubmatch(rawURL); m != nil {
+			u := &url.URL{
+				Scheme: "ssh",
+				Host:   m[2],
+				Path:   "/" + strings.TrimPrefix(m[3], "/"),
+			}
+			if m[1] != "" {
+				u.User = url.User(m[1])
+			}
+			return u, nil
+		}
+	}
+	return url.Parse(rawURL)
+}
@@ -0,0 +1,48 @@
+package git
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "scp-like",
+			url:  "git@github.com:fal-ai/demo.git",
+			want: "ssh://git@github.com/fal-ai/demo.git",
+		},
+		{
+			name: "scp-like without user",
+			url:  "github.com:fal-ai/demo.git",
+			want: "ssh://github.com/fal-ai/demo.git",
+		},
+		{
+			name: "scp-like absolute path",
+			url:  "deploy@git.example.com:/srv/git/demo.git",
+			want: "ssh://deploy@git.example.com/srv/git/demo.git",
+		},
+		{
+			name: "ssh with port",
+			url:  "ssh://git@github.com:2222/fal-ai/demo.git",
+			want: "ssh://git@github.com:2222/fal-ai/demo.git",
+		},
+		{
+			name: "https",
+			url:  "https://github.com/fal-ai/demo.git",
+			want: "https://github.com/fal-ai/demo.git",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := ParseURL(tt.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := u.String(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PythonIndex are the package indexes uv installs from instead of PyPI.
+type PythonIndex struct {
+	// URL is the index replacing PyPI, PyPI if empty.
+	URL string
+	// ExtraURLs are indexes searched before URL.
+	ExtraURLs []string
+	// Username and Password authenticate to all of the indexes.
+	Username string
+	Password string
+}
+
+// String identifies the indexes, leaving out the credentials.
+func (i *PythonIndex) String() string {
+	if i == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %q", i.URL, i.ExtraURLs)
+}
+
+// uvEnvironment returns the uv environment variables selecting the indexes.
+// Every index is named, so credentials can be passed per index without
+// putting them into URLs.
+func (i *PythonIndex) uvEnvironment() map[string]string {
+	env := map[string]string{}
+	if i == nil {
+		return env
+	}
+
+	named := func(name, indexURL string) string {
+		if i.Username != "" || i.Password != "" {
+			prefix := "UV_INDEX_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+			env[prefix+"_USERNAME"] = i.Username
+			env[prefix+"_PASSWORD"] = i.Password
+		}
+		return name + "=" + indexURL
+	}
+
+	if i.URL != "" {
+		env["UV_DEFAULT_INDEX"] = named("fal-default", i.URL)
+	}
+	if len(i.ExtraURLs) > 0 {
+		indexes := make([]string, len(i.ExtraURLs))
+		for n, indexURL := range i.ExtraURLs {
+			indexes[n] = named(fmt.Sprintf("fal-extra-%d", n+1), indexURL)
+		}
+		env["UV_INDEX"] = strings.Join(indexes, " ")
+	}
+	return env
+}
+
+// pipEnvironment returns the pip environment variables selecting the
+// indexes. pip only takes credentials as part of the URLs.
+func (i *PythonIndex) pipEnvironment() map[string]string {
+	env := map[string]string{}
+	if i == nil {
+		return env
+	}
+
+	withCredentials := func(rawURL string) string {
+		u, err := url.Parse(rawURL)
+		if err != nil || (i.Username == "" && i.Password == "") {
+			return rawURL
+		}
+		u.User = url.UserPassword(i.Username, i.Password)
+		return u.String()
+	}
+
+	if i.URL != "" {
+		env["PIP_INDEX_URL"] = withCredentials(i.URL)
+	}
+	if len(i.ExtraURLs) > 0 {
+		extra := make([]string, len(i.ExtraURLs))
+		for n, u := range i.ExtraURLs {
+			extra[n] = withCredentials(u)
+		}
+		env["PIP_EXTRA_INDEX_URL"] = strings.Join(extra, " ")
+	}
+	return env
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPythonIndexUvEnvironment(t *testing.T) {
+	tests := []struct {
+		name  string
+		index *PythonIndex
+		want  map[string]string
+	}{
+		{
+			name: "none",
+			want: map[string]string{},
+		},
+		{
+			name:  "default only",
+			index: &PythonIndex{URL: "https://pypi.example.com/simple"},
+			want: map[string]string{
+				"UV_DEFAULT_INDEX": "fal-default=https://pypi.example.com/simple",
+			},
+		},
+		{
+			name: "extra with credentials",
+			index: &PythonIndex{
+				ExtraURLs: []string{"https://a.example.com/simple", "https://b.example.com/simple"},
+				Username:  "deploy",
+				Password:  "secret",
+			},
+			want: map[string]string{
+				"UV_INDEX":                      "fal-extra-1=https://a.example.com/simple fal-extra-2=https://b.example.com/simple",
+				"UV_INDEX_FAL_EXTRA_1_USERNAME": "deploy",
+				"UV_INDEX_FAL_EXTRA_1_PASSWORD": "secret",
+				"UV_INDEX_FAL_EXTRA_2_USERNAME": "deploy",
+				"UV_INDEX_FAL_EXTRA_2_PASSWORD": "secret",
+			},
+		},
+		{
+			name:  "default with username",
+			index: &PythonIndex{URL: "https://pypi.example.com/simple", Username: "deploy"},
+			want: map[string]string{
+				"UV_DEFAULT_INDEX":              "fal-default=https://pypi.example.com/simple",
+				"UV_INDEX_FAL_DEFAULT_USERNAME": "deploy",
+				"UV_INDEX_FAL_DEFAULT_PASSWORD": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.index.uvEnvironment(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPythonIndexStringOmitsCredentials(t *testing.T) {
+	index := &PythonIndex{URL: "https://pypi.example.com/simple", Username: "deploy", Password: "secret"}
+	if s := index.String(); strings.Contains(s, "deploy") || strings.Contains(s, "secret") {
+		t.Errorf("expected no credentials in %q", s)
+	}
+}
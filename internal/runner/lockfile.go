@@ -0,0 +1,46 @@
+package runner
+
+// LockfileMode is how a runner treats the lockfile of a project it syncs.
+type LockfileMode string
+
+const (
+	// LockfileDefault uses uv.lock if it is up to date with pyproject.toml
+	// and updates it otherwise.
+	LockfileDefault LockfileMode = ""
+	// LockfileFrozen installs what uv.lock pins without checking it is up to
+	// date with pyproject.toml.
+	LockfileFrozen LockfileMode = "frozen"
+	// LockfileLocked fails if uv.lock is missing or not up to date with
+	// pyproject.toml.
+	LockfileLocked LockfileMode = "locked"
+	// LockfileIgnore resolves the dependencies again, ignoring the versions
+	// uv.lock pins.
+	LockfileIgnore LockfileMode = "ignore"
+)
+
+// LockfileModes lists the modes that can be configured.
+var LockfileModes = []string{string(LockfileFrozen), string(LockfileLocked), string(LockfileIgnore)}
+
+func (m LockfileMode) syncFlags() []string {
+	switch m {
+	case LockfileFrozen:
+		return []string{"--frozen"}
+	case LockfileLocked:
+		return []string{"--locked"}
+	case LockfileIgnore:
+		return []string{"--upgrade"}
+	}
+	return nil
+}
+
+// runFlags returns the flags that keep uv run, which syncs the project
+// before running a command, from changing what uv sync installed with m.
+func (m LockfileMode) runFlags() []string {
+	switch m {
+	case LockfileFrozen:
+		return []string{"--frozen"}
+	case LockfileLocked:
+		return []string{"--locked"}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLockfileModeFlags(t *testing.T) {
+	tests := []struct {
+		mode LockfileMode
+		sync []string
+		run  []string
+	}{
+		{mode: LockfileDefault},
+		{mode: LockfileFrozen, sync: []string{"--frozen"}, run: []string{"--frozen"}},
+		{mode: LockfileLocked, sync: []string{"--locked"}, run: []string{"--locked"}},
+		{mode: LockfileIgnore, sync: []string{"--upgrade"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if got := tt.mode.syncFlags(); !reflect.DeepEqual(got, tt.sync) {
+				t.Errorf("expected sync flags %q, got %q", tt.sync, got)
+			}
+			if got := tt.mode.runFlags(); !reflect.DeepEqual(got, tt.run) {
+				t.Errorf("expected run flags %q, got %q", tt.run, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/command"
+)
+
+const (
+	commandPython = "python3"
+
+	// venvDir is the virtual environment of a project run with pip.
+	venvDir = ".venv"
+	// requirementsFile is installed instead of the project if it exists.
+	requirementsFile = "requirements.txt"
+)
+
+type pip struct {
+	path        string
+	environment map[string]string
+	lockfile    LockfileMode
+}
+
+var _ Runner = (*pip)(nil)
+
+// bin returns the path of the executable name in the virtual environment.
+func (p *pip) bin(name string) string {
+	return filepath.Join(p.path, venvDir, "bin", name)
+}
+
+func (p *pip) venvOpts() []command.Opt {
+	return []command.Opt{command.WithEnvironmentVariables(p.environment), command.WithArgs("-m", "venv", venvDir), command.WithDirectory(p.path)}
+}
+
+func (p *pip) installOpts(args ...string) []command.Opt {
+	if p.lockfile == LockfileIgnore {
+		args = append([]string{"--upgrade"}, args...)
+	}
+	return []command.Opt{command.WithEnvironmentVariables(p.environment), command.WithArgs("install", args...), command.WithDirectory(p.path)}
+}
+
+func (p *pip) venv(ctx context.Context) (<-chan []byte, error) {
+	return command.Exec(ctx, commandPython, p.venvOpts()...)
+}
+
+func (p *pip) install(ctx context.Context, args ...string) (<-chan []byte, error) {
+	return command.Exec(ctx, p.bin("pip"), p.installOpts(args...)...)
+}
+
+func (p *pip) Bootstrap(ctx context.Context) (<-chan []byte, error) {
+	return sequence(
+		func() (<-chan []byte, error) { return p.venv(ctx) },
+		func() (<-chan []byte, error) { return p.install(ctx, "fal") },
+	)
+}
+
+// Sync installs requirements.txt if the project has one and the project
+// itself otherwise.
+func (p *pip) Sync(ctx context.Context) ([]byte, error) {
+	args := []string{"."}
+	if _, err := os.Stat(filepath.Join(p.path, requirementsFile)); err == nil {
+		args = []string{"-r", requirementsFile}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	out, err := command.Run(ctx, commandPython, p.venvOpts()...)
+	if err != nil {
+		return out, err
+	}
+	installed, err := command.Run(ctx, p.bin("pip"), p.installOpts(args...)...)
+	return append(out, installed...), err
+}
+
+// Run runs args[0] from the virtual environment, as if it were activated.
+func (p *pip) Run(ctx context.Context, environment map[string]string, args ...string) (<-chan []byte, error) {
+	return command.Exec(ctx, p.bin(args[0]), p.runOpts(environment, args[1:])...)
+}
+
+func (p *pip) Output(ctx context.Context, environment map[string]string, args ...string) ([]byte, error) {
+	return command.Run(ctx, p.bin(args[0]), p.runOpts(environment, args[1:])...)
+}
+
+func (p *pip) runOpts(environment map[string]string, args []string) []command.Opt {
+	env := maps.Clone(p.environment)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, environment)
+	env["VIRTUAL_ENV"] = filepath.Join(p.path, venvDir)
+	env["PATH"] = filepath.Join(p.path, venvDir, "bin") + string(os.PathListSeparator) + os.Getenv("PATH")
+	return argOpts(env, p.path, args)
+}
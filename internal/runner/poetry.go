@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/command"
+)
+
+const (
+	commandPoetry = "poetry"
+)
+
+type poetry struct {
+	path        string
+	environment map[string]string
+	lockfile    LockfileMode
+}
+
+var _ Runner = (*poetry)(nil)
+
+func (p *poetry) exec(ctx context.Context, args ...string) (<-chan []byte, error) {
+	return command.Exec(ctx, commandPoetry, command.WithEnvironmentVariables(p.environment), command.WithArgs(args[0], args[1:]...), command.WithDirectory(p.path))
+}
+
+// Bootstrap creates a project depending on fal, unless an earlier call did.
+func (p *poetry) Bootstrap(ctx context.Context) (<-chan []byte, error) {
+	if _, err := os.Stat(filepath.Join(p.path, "pyproject.toml")); err == nil {
+		return p.exec(ctx, "add", "fal")
+	}
+	return sequence(
+		func() (<-chan []byte, error) {
+			return p.exec(ctx, "init", "--no-interaction", "--name", "fal-runner")
+		},
+		func() (<-chan []byte, error) { return p.exec(ctx, "add", "fal") },
+	)
+}
+
+// Sync installs the project's dependencies without the project itself.
+// poetry install refuses a poetry.lock that is out of date but creates a
+// missing one, so the locked mode checks it exists first.
+func (p *poetry) Sync(ctx context.Context) ([]byte, error) {
+	args := []string{"install", "--no-root"}
+	switch p.lockfile {
+	case LockfileIgnore:
+		args = []string{"update"}
+	case LockfileLocked:
+		_, err := os.Stat(filepath.Join(p.path, KindPoetry.Lockfile()))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s is missing", KindPoetry.Lockfile())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return command.Run(ctx, commandPoetry, command.WithEnvironmentVariables(p.environment), command.WithArgs(args[0], args[1:]...), command.WithDirectory(p.path))
+}
+
+func (p *poetry) Run(ctx context.Context, environment map[string]string, args ...string) (<-chan []byte, error) {
+	return command.Exec(ctx, commandPoetry, p.runOpts(environment, args)...)
+}
+
+func (p *poetry) Output(ctx context.Context, environment map[string]string, args ...string) ([]byte, error) {
+	return command.Run(ctx, commandPoetry, p.runOpts(environment, args)...)
+}
+
+func (p *poetry) runOpts(environment map[string]string, args []string) []command.Opt {
+	env := maps.Clone(p.environment)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, environment)
+	return []command.Opt{command.WithEnvironmentVariables(env), command.WithArgs("run", args...), command.WithDirectory(p.path)}
+}
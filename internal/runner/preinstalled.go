@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/command"
+)
+
+// preinstalled runs commands found on PATH, for images that ship the fal CLI
+// and the dependencies of every project.
+type preinstalled struct {
+	path        string
+	environment map[string]string
+}
+
+var _ Runner = (*preinstalled)(nil)
+
+func (p *preinstalled) Bootstrap(ctx context.Context) (<-chan []byte, error) {
+	if _, err := exec.LookPath("fal"); err != nil {
+		return done(), fmt.Errorf("the fal CLI is not installed: %w", err)
+	}
+	return done(), nil
+}
+
+// Sync installs nothing, the dependencies are expected to be installed.
+func (p *preinstalled) Sync(ctx context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (p *preinstalled) Run(ctx context.Context, environment map[string]string, args ...string) (<-chan []byte, error) {
+	return command.Exec(ctx, args[0], p.runOpts(environment, args[1:])...)
+}
+
+func (p *preinstalled) Output(ctx context.Context, environment map[string]string, args ...string) ([]byte, error) {
+	return command.Run(ctx, args[0], p.runOpts(environment, args[1:])...)
+}
+
+func (p *preinstalled) runOpts(environment map[string]string, args []string) []command.Opt {
+	env := maps.Clone(p.environment)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, environment)
+	return argOpts(env, p.path, args)
+}
@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/fal-ai/terraform-provider-fal/internal/command"
+)
+
+// Runner manages the Python environment the fal CLI runs in.
+type Runner interface {
+	// Bootstrap installs the fal CLI into the runner's empty directory, for
+	// fal commands that are not tied to a project.
+	Bootstrap(ctx context.Context) (<-chan []byte, error)
+	// Sync installs the dependencies of the project at the runner's path and
+	// returns the output once it is done. A failing install is an error.
+	Sync(ctx context.Context) ([]byte, error)
+	// Run runs a command installed in the environment, with environment set
+	// in addition to the runner's.
+	Run(ctx context.Context, environment map[string]string, args ...string) (<-chan []byte, error)
+	// Output runs a command like Run and returns its output once it exits. A
+	// non-zero exit status is an error.
+	Output(ctx context.Context, environment map[string]string, args ...string) ([]byte, error)
+}
+
+// Kind names a Runner implementation.
+type Kind string
+
+const (
+	// KindUv manages environments with uv.
+	KindUv Kind = "uv"
+	// KindPip creates virtual environments with `python3 -m venv` and
+	// installs into them with pip.
+	KindPip Kind = "pip"
+	// KindPoetry manages environments with Poetry.
+	KindPoetry Kind = "poetry"
+	// KindFal runs a fal CLI that is already installed, along with the
+	// dependencies of every project.
+	KindFal Kind = "fal"
+)
+
+// Kinds lists the runners that can be configured.
+var Kinds = []string{string(KindUv), string(KindPip), string(KindPoetry), string(KindFal)}
+
+// Options configure a Runner.
+type Options struct {
+	// Environment is set for every command, e.g. to route it through a
+	// proxy.
+	Environment map[string]string
+	// PythonIndex selects the package indexes to install from instead of
+	// PyPI.
+	PythonIndex *PythonIndex
+	// Lockfile is how Sync treats the project's lockfile.
+	Lockfile LockfileMode
+}
+
+// New returns a runner of kind for the project at path.
+func New(kind Kind, path string, opts Options) (Runner, error) {
+	if err := Validate(kind, opts); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case KindUv, "":
+		return &uv{path: path, environment: merge(opts.Environment, opts.PythonIndex.uvEnvironment()), lockfile: opts.Lockfile}, nil
+	case KindPip:
+		return &pip{path: path, environment: merge(opts.Environment, opts.PythonIndex.pipEnvironment()), lockfile: opts.Lockfile}, nil
+	case KindPoetry:
+		return &poetry{path: path, environment: opts.Environment, lockfile: opts.Lockfile}, nil
+	case KindFal:
+		return &preinstalled{path: path, environment: opts.Environment}, nil
+	default:
+		return nil, fmt.Errorf("unknown runner %q", kind)
+	}
+}
+
+// Validate returns an error if runners of kind do not support opts, so
+// configurations can be rejected before anything runs. Unknown kinds are
+// left to New.
+func Validate(kind Kind, opts Options) error {
+	switch kind {
+	case KindPip:
+		if opts.Lockfile == LockfileFrozen || opts.Lockfile == LockfileLocked {
+			return fmt.Errorf("lockfile mode %s is not supported by the pip runner, pip has no lockfile", opts.Lockfile)
+		}
+	case KindPoetry:
+		if opts.PythonIndex != nil {
+			return fmt.Errorf("python_index is not supported by the poetry runner, configure sources in pyproject.toml instead")
+		}
+		if opts.Lockfile == LockfileFrozen {
+			return fmt.Errorf("lockfile mode %s is not supported by the poetry runner", opts.Lockfile)
+		}
+	case KindFal:
+		if opts.PythonIndex != nil {
+			return fmt.Errorf("python_index is not supported by the fal runner, which installs nothing")
+		}
+		if opts.Lockfile != LockfileDefault {
+			return fmt.Errorf("lockfile mode %s is not supported by the fal runner, which installs nothing", opts.Lockfile)
+		}
+	}
+	return nil
+}
+
+// Lockfile returns the name of the lockfile runners of kind install a project
+// from, or an empty string if they do not use one.
+func (k Kind) Lockfile() string {
+	switch k {
+	case KindUv, "":
+		return "uv.lock"
+	case KindPoetry:
+		return "poetry.lock"
+	}
+	return ""
+}
+
+func merge(environments ...map[string]string) map[string]string {
+	env := map[string]string{}
+	for _, e := range environments {
+		maps.Copy(env, e)
+	}
+	return env
+}
+
+// sequence runs steps one after another and streams their combined output.
+// Steps after the first start once the output of the previous one ends.
+func sequence(steps ...func() (<-chan []byte, error)) (<-chan []byte, error) {
+	first, err := steps[0]()
+	if err != nil {
+		return first, err
+	}
+
+	c := make(chan []byte, 256)
+	go func() {
+		defer close(c)
+		for line := range first {
+			c <- line
+		}
+		for _, step := range steps[1:] {
+			out, err := step()
+			if err != nil {
+				c <- []byte(err.Error() + "\n")
+				return
+			}
+			for line := range out {
+				c <- line
+			}
+		}
+	}()
+	return c, nil
+}
+
+// argOpts returns the options running a command with args, which may be
+// empty.
+func argOpts(environment map[string]string, dir string, args []string) []command.Opt {
+	opts := []command.Opt{command.WithEnvironmentVariables(environment), command.WithDirectory(dir)}
+	if len(args) > 0 {
+		opts = append(opts, command.WithArgs(args[0], args[1:]...))
+	}
+	return opts
+}
+
+// done returns the output of a step that has nothing to run. Steps return it
+// with their errors too, since callers read the output to report them.
+func done() <-chan []byte {
+	c := make(chan []byte)
+	close(c)
+	return c
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	index := &PythonIndex{URL: "https://pypi.example.com/simple"}
+	tests := []struct {
+		name    string
+		kind    Kind
+		opts    Options
+		wantErr string
+	}{
+		{name: "default", kind: "", opts: Options{PythonIndex: index, Lockfile: LockfileFrozen}},
+		{name: "uv", kind: KindUv, opts: Options{Lockfile: LockfileLocked}},
+		{name: "pip", kind: KindPip, opts: Options{PythonIndex: index, Lockfile: LockfileIgnore}},
+		{name: "pip locked", kind: KindPip, opts: Options{Lockfile: LockfileLocked}, wantErr: "lockfile mode locked is not supported by the pip runner"},
+		{name: "poetry", kind: KindPoetry, opts: Options{Lockfile: LockfileLocked}},
+		{name: "poetry frozen", kind: KindPoetry, opts: Options{Lockfile: LockfileFrozen}, wantErr: "lockfile mode frozen is not supported by the poetry runner"},
+		{name: "poetry index", kind: KindPoetry, opts: Options{PythonIndex: index}, wantErr: "python_index is not supported by the poetry runner"},
+		{name: "fal", kind: KindFal},
+		{name: "fal ignore", kind: KindFal, opts: Options{Lockfile: LockfileIgnore}, wantErr: "lockfile mode ignore is not supported by the fal runner"},
+		{name: "unknown", kind: "conda", wantErr: `unknown runner "conda"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.kind, t.TempDir(), tt.opts)
+			if tt.wantErr == "" {
+				if err != nil || r == nil {
+					t.Fatalf("expected a runner, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKindLockfile(t *testing.T) {
+	tests := map[Kind]string{
+		"":         "uv.lock",
+		KindUv:     "uv.lock",
+		KindPip:    "",
+		KindPoetry: "poetry.lock",
+		KindFal:    "",
+	}
+	for kind, want := range tests {
+		if got := kind.Lockfile(); got != want {
+			t.Errorf("%q: expected %q, got %q", kind, want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"maps"
 
 	"github.com/fal-ai/terraform-provider-fal/internal/command"
 )
@@ -11,29 +12,53 @@ const (
 )
 
 type uv struct {
-	path string
+	path        string
+	environment map[string]string
+	lockfile    LockfileMode
 }
 
-func FromUv(path string) *uv {
-	return &uv{path: path}
-}
+var _ Runner = (*uv)(nil)
 
 func (u *uv) Init(ctx context.Context) (<-chan []byte, error) {
-	return command.Exec(ctx, commandUv, command.WithArgs("init", "--no-workspace", "--bare"), command.WithDirectory(u.path))
+	return command.Exec(ctx, commandUv, command.WithEnvironmentVariables(u.environment), command.WithArgs("init", "--no-workspace", "--bare"), command.WithDirectory(u.path))
 }
 
 func (u *uv) Venv(ctx context.Context) (<-chan []byte, error) {
-	return command.Exec(ctx, commandUv, command.WithArgs("venv"), command.WithDirectory(u.path))
+	return command.Exec(ctx, commandUv, command.WithEnvironmentVariables(u.environment), command.WithArgs("venv"), command.WithDirectory(u.path))
 }
 
 func (u *uv) Add(ctx context.Context, packages ...string) (<-chan []byte, error) {
-	return command.Exec(ctx, commandUv, command.WithArgs("add", packages...), command.WithDirectory(u.path))
+	return command.Exec(ctx, commandUv, command.WithEnvironmentVariables(u.environment), command.WithArgs("add", packages...), command.WithDirectory(u.path))
+}
+
+func (u *uv) Bootstrap(ctx context.Context) (<-chan []byte, error) {
+	return sequence(
+		func() (<-chan []byte, error) { return u.Init(ctx) },
+		func() (<-chan []byte, error) { return u.Add(ctx, "fal") },
+	)
 }
 
+// Run runs args with uv run, which syncs the project first with the flags
+// of the lockfile mode.
 func (u *uv) Run(ctx context.Context, environment map[string]string, args ...string) (<-chan []byte, error) {
-	return command.Exec(ctx, commandUv, command.WithEnvironmentVariables(environment), command.WithArgs("run", args...), command.WithDirectory(u.path))
+	return command.Exec(ctx, commandUv, u.runOpts(environment, args)...)
+}
+
+func (u *uv) Output(ctx context.Context, environment map[string]string, args ...string) ([]byte, error) {
+	return command.Run(ctx, commandUv, u.runOpts(environment, args)...)
+}
+
+func (u *uv) runOpts(environment map[string]string, args []string) []command.Opt {
+	env := maps.Clone(u.environment)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, environment)
+	return []command.Opt{command.WithEnvironmentVariables(env), command.WithArgs("run", append(u.lockfile.runFlags(), args...)...), command.WithDirectory(u.path)}
 }
 
-func (u *uv) Sync(ctx context.Context) (<-chan []byte, error) {
-	return command.Exec(ctx, commandUv, command.WithArgs("sync"), command.WithDirectory(u.path))
+// Sync installs the project's dependencies, treating its uv.lock as the
+// lockfile mode says.
+func (u *uv) Sync(ctx context.Context) ([]byte, error) {
+	return command.Run(ctx, commandUv, command.WithEnvironmentVariables(u.environment), command.WithArgs("sync", u.lockfile.syncFlags()...), command.WithDirectory(u.path))
 }
@@ -0,0 +1,40 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// appNameRe matches the names fal accepts for apps and aliases.
+var appNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+type appNameValidator struct{}
+
+func (v appNameValidator) Description(ctx context.Context) string {
+	return "app name must consist of lowercase letters, digits and dashes, and start and end with a letter or digit"
+}
+
+func (v appNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v appNameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	if appNameRe.MatchString(req.ConfigValue.ValueString()) {
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid app name",
+		fmt.Sprintf("%q is not a valid fal app name: it must consist of lowercase letters, digits and dashes, and start and end with a letter or digit.", req.ConfigValue.ValueString()),
+	)
+}
+
+func AppName() validator.String {
+	return appNameValidator{}
+}
@@ -3,8 +3,8 @@ package validators
 import (
 	"context"
 	"fmt"
-	"net/url"
 
+	"github.com/fal-ai/terraform-provider-fal/internal/git"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 )
 
@@ -24,7 +24,8 @@ func (v urlSchemeValidator) ValidateString(ctx context.Context, req validator.St
 	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
 		return
 	}
-	u, err := url.Parse(req.ConfigValue.ValueString())
+	// scp-like URLs such as git@github.com:owner/repo.git count as ssh
+	u, err := git.ParseURL(req.ConfigValue.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddAttributeError(req.Path, "could not parse url", err.Error())
 		return